@@ -0,0 +1,250 @@
+// Package anomaly detects anomalies and change points in timeserie Supports.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/decompose"
+)
+
+// Anomaly is a flagged point.
+type Anomaly struct {
+	On     time.Time
+	Value  float64 // Value of the point.
+	Score  float64 // Anomaly score of the point.
+	Reason string  // Human readable reason why the point was flagged.
+}
+
+// Report is the result of a detection.
+type Report struct {
+	Scores    *timeserie.Support // Anomaly score of each point.
+	Anomalies []Anomaly          // Flagged points in chronological order.
+}
+
+// points returns the times and values of 's'.
+func points(s *timeserie.Support) ([]time.Time, []float64) {
+	times, values := make([]time.Time, 0, s.Len()), make([]float64, 0, s.Len())
+	for on, v := range s.Values() {
+		times, values = append(times, on), append(values, v)
+	}
+	return times, values
+}
+
+// flag appends the point to the report, and flags it if 'flagged'.
+func (r *Report) flag(on time.Time, value, score float64, flagged bool, reason string, args ...any) {
+	r.Scores.Append(on, score)
+	if flagged {
+		r.Anomalies = append(r.Anomalies, Anomaly{On: on, Value: value, Score: score, Reason: fmt.Sprintf(reason, args...)})
+	}
+}
+
+// newReport returns an empty report.
+func newReport() *Report { return &Report{Scores: new(timeserie.Support)} }
+
+// ZScore flags points whose z-score, relative to the mean and standard deviation of the 'window'
+// previous points, exceeds 'threshold' in absolute value.
+//
+// The first 'window' points are not scored. An error is returned if 'window' is not positive.
+func ZScore(s *timeserie.Support, window int, threshold float64) (*Report, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("z-score window must be positive got %v", window)
+	}
+	times, values := points(s)
+	r := newReport()
+	for i := window; i < len(values); i++ {
+		m, sd := meanStd(values[i-window : i])
+		z := (values[i] - m) / sd
+		if sd == 0 {
+			z = 0
+			if values[i] != m {
+				z = math.Inf(1)
+			}
+		}
+		r.flag(times[i], values[i], z, math.Abs(z) > threshold, "z-score %.3g exceeds %g", z, threshold)
+	}
+	return r, nil
+}
+
+// Hampel flags points that are more than 'threshold' scaled median absolute deviations away from the
+// median of the centered window of 2*'half'+1 points.
+//
+// Windows are truncated at the support bounds. An error is returned if 'half' is negative.
+func Hampel(s *timeserie.Support, half int, threshold float64) (*Report, error) {
+	if half < 0 {
+		return nil, fmt.Errorf("Hampel half window must not be negative got %v", half)
+	}
+	times, values := points(s)
+	r := newReport()
+	for i, v := range values {
+		w := values[max(0, i-half):min(len(values), i+half+1)]
+		m, mad := medianMAD(w)
+		score := robustScore(v, m, mad)
+		r.flag(times[i], v, score, score > threshold, "%.3g scaled MAD away from median %g exceeds %g", score, m, threshold)
+	}
+	return r, nil
+}
+
+// Seasonal flags points whose residual, after removing the trend and the additive seasonality, is
+// more than 'threshold' scaled median absolute deviations away from the median residual.
+//
+// Trend and seasonality are estimated with a robust decompose.STL over 7 seasons, so that anomalies
+// do not leak into their neighbours. An error is returned if the season has less than 2 points, or
+// if the support cannot be decomposed.
+func Seasonal(s *timeserie.Support, season decompose.Season, threshold float64) (*Report, error) {
+	if season.Period < 2 {
+		return nil, fmt.Errorf("seasonal period must be at least 2 got %v", season.Period)
+	}
+	d, err := decompose.STL(s, season, 7, true)
+	if err != nil {
+		return nil, err
+	}
+	times, residuals := points(d.Residual)
+	m, mad := medianMAD(residuals)
+	f := timeserie.New(s, timeserie.ModeNullset)
+	r := newReport()
+	for i, e := range residuals {
+		score := robustScore(e, m, mad)
+		r.flag(times[i], f.F(times[i]), score, score > threshold, "seasonal residual %.3g is %.3g scaled MAD away, exceeds %g", e, score, threshold)
+	}
+	return r, nil
+}
+
+// CUSUM detects shifts of the mean using a two-sided cumulative sum of the values standardized by
+// the mean and standard deviation of a reference of 'window' points. A change is flagged when a
+// cumulative sum exceeds 'threshold', the reference is then estimated again on the next 'window'
+// points. 'drift' is the allowed slack per point, in standard deviations.
+//
+// Reference points are not scored. An error is returned if 'window' is not positive.
+func CUSUM(s *timeserie.Support, window int, threshold, drift float64) (*Report, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("CUSUM window must be positive got %v", window)
+	}
+	times, values := points(s)
+	r := newReport()
+	var up, down, m, sd float64
+	for ref := 0; ref+window < len(values); {
+		m, sd = meanStd(values[ref : ref+window])
+		if sd == 0 {
+			sd = 1
+		}
+		up, down = 0, 0
+		i := ref + window
+		for ; i < len(values); i++ {
+			v := values[i]
+			z := (v - m) / sd
+			up, down = max(0, up+z-drift), max(0, down-z-drift)
+			score := max(up, down)
+			r.flag(times[i], v, score, score > threshold, "cumulative sum %.3g exceeds %g", score, threshold)
+			if score > threshold {
+				break
+			}
+		}
+		ref = i + 1
+	}
+	return r, nil
+}
+
+// PELT detects changes of the mean using the Pruned Exact Linear Time algorithm with a squared error
+// cost and a 'penalty' per change point. The score of a change point is the absolute difference
+// between the means of the segments before and after it, other points score 0.
+//
+// An error is returned if 'penalty' is negative.
+func PELT(s *timeserie.Support, penalty float64) (*Report, error) {
+	if penalty < 0 {
+		return nil, fmt.Errorf("PELT penalty must not be negative got %v", penalty)
+	}
+	times, values := points(s)
+	n := len(values)
+	// prefix sums for O(1) segment cost.
+	sum, sum2 := make([]float64, n+1), make([]float64, n+1)
+	for i, v := range values {
+		sum[i+1], sum2[i+1] = sum[i]+v, sum2[i]+v*v
+	}
+	cost := func(a, b int) float64 { // cost of values[a:b]
+		d := sum[b] - sum[a]
+		return sum2[b] - sum2[a] - d*d/float64(b-a)
+	}
+	f := make([]float64, n+1)
+	last := make([]int, n+1)
+	f[0] = -penalty
+	candidates := []int{0}
+	for t := 1; t <= n; t++ {
+		best, arg := math.Inf(1), 0
+		costs := make([]float64, len(candidates))
+		for i, c := range candidates {
+			costs[i] = f[c] + cost(c, t)
+			if costs[i]+penalty < best {
+				best, arg = costs[i]+penalty, c
+			}
+		}
+		f[t], last[t] = best, arg
+		// prune candidates that can never be optimal.
+		pruned := candidates[:0]
+		for i, c := range candidates {
+			if costs[i] <= f[t] {
+				pruned = append(pruned, c)
+			}
+		}
+		candidates = append(pruned, t)
+	}
+	// backtrack change points.
+	var changes []int
+	for t := last[n]; t > 0; t = last[t] {
+		changes = append(changes, t)
+	}
+	slices.Reverse(changes)
+
+	r := newReport()
+	bounds := append(append([]int{0}, changes...), n)
+	segmentMean := func(a, b int) float64 { return (sum[b] - sum[a]) / float64(b-a) }
+	for k := 1; k < len(bounds); k++ {
+		for i := bounds[k-1]; i < bounds[k]; i++ {
+			score := 0.0
+			if i == bounds[k-1] && k > 1 {
+				score = math.Abs(segmentMean(i, bounds[k]) - segmentMean(bounds[k-2], i))
+			}
+			r.flag(times[i], values[i], score, score > 0, "mean changes by %.3g", score)
+		}
+	}
+	return r, nil
+}
+
+// meanStd returns the mean and the standard deviation of 'values'.
+func meanStd(values []float64) (m, sd float64) {
+	for _, v := range values {
+		m += v
+	}
+	m /= float64(len(values))
+	for _, v := range values {
+		sd += (v - m) * (v - m)
+	}
+	return m, math.Sqrt(sd / float64(len(values)))
+}
+
+// medianMAD returns the median and the median absolute deviation of 'values'.
+func medianMAD(values []float64) (m, mad float64) {
+	m = timeserie.Median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - m)
+	}
+	return m, timeserie.Median(deviations)
+}
+
+// robustScore returns the distance between 'v' and 'm' in scaled median absolute deviations.
+//
+// The MAD is scaled to be a consistent estimator of the standard deviation of a normal distribution.
+func robustScore(v, m, mad float64) float64 {
+	d := math.Abs(v - m)
+	if mad == 0 {
+		if d == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return d / (1.4826 * mad)
+}
@@ -0,0 +1,116 @@
+package anomaly_test
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/anomaly"
+	"github.com/etnz/timeserie/decompose"
+)
+
+// series returns a daily support starting on 2000-1-1 with 'n' values computed by 'f'.
+func series(n int, f func(i int) float64) *timeserie.Support {
+	s := new(timeserie.Support)
+	for i := 0; i < n; i++ {
+		s.Append(day(i), f(i))
+	}
+	return s
+}
+
+// day returns the i-th day after 2000-1-1.
+func day(i int) time.Time { return timeserie.DayDate(2000, 1, 1+i) }
+
+// noise is a deterministic gaussian noise of standard deviation 0.1.
+var noise = func() func(i int) float64 {
+	r := rand.New(rand.NewPCG(1, 2))
+	x := make([]float64, 1000)
+	for i := range x {
+		x[i] = 0.1 * r.NormFloat64()
+	}
+	return func(i int) float64 { return x[i] }
+}()
+
+// flagged returns the flagged times in the report.
+func flagged(r *anomaly.Report) []time.Time {
+	var times []time.Time
+	for _, a := range r.Anomalies {
+		times = append(times, a.On)
+	}
+	return times
+}
+
+// TestDetectors checks that each detector flags a single outlier.
+func TestDetectors(t *testing.T) {
+	outlier := func(f func(i int) float64) func(i int) float64 {
+		return func(i int) float64 {
+			if i == 40 {
+				return f(i) + 5
+			}
+			return f(i)
+		}
+	}
+	flat := series(60, outlier(func(i int) float64 { return 10 + noise(i) }))
+	seasonal := series(60, outlier(func(i int) float64 { return 10 + noise(i) + []float64{0, 1, 2, 1, 0, -1, -2}[i%7] }))
+	for _, test := range []struct {
+		name   string
+		detect func() (*anomaly.Report, error)
+	}{
+		{"zscore", func() (*anomaly.Report, error) { return anomaly.ZScore(flat, 14, 4) }},
+		{"hampel", func() (*anomaly.Report, error) { return anomaly.Hampel(flat, 5, 5) }},
+		{"seasonal", func() (*anomaly.Report, error) { return anomaly.Seasonal(seasonal, decompose.Weekly, 5) }},
+	} {
+		report, err := test.detect()
+		if err != nil {
+			t.Fatalf("%s error: %v", test.name, err)
+		}
+		if x := flagged(report); len(x) != 1 || x[0] != day(40) {
+			t.Errorf("%s flagged %v want [%v]", test.name, x, day(40))
+		}
+		if report.Scores.Len() == 0 {
+			t.Errorf("%s has no scores", test.name)
+		}
+	}
+}
+
+// TestChangePoints checks that a mean shift is detected.
+func TestChangePoints(t *testing.T) {
+	s := series(100, func(i int) float64 {
+		if i < 50 {
+			return noise(i)
+		}
+		return 3 + noise(i)
+	})
+	pelt, err := anomaly.PELT(s, 5)
+	if err != nil {
+		t.Fatalf("PELT() error: %v", err)
+	}
+	if x := flagged(pelt); len(x) != 1 || x[0] != day(50) {
+		t.Errorf("PELT() flagged %v want [%v]", x, day(50))
+	}
+	cusum, err := anomaly.CUSUM(s, 10, 5, 0.5)
+	if err != nil {
+		t.Fatalf("CUSUM() error: %v", err)
+	}
+	if x := flagged(cusum); len(x) != 1 || x[0] != day(50) {
+		t.Errorf("CUSUM() flagged %v want [%v]", x, day(50))
+	}
+}
+
+// TestInvalid checks that invalid parameters are rejected instead of panicking.
+func TestInvalid(t *testing.T) {
+	s := series(10, noise)
+	for name, detect := range map[string]func() (*anomaly.Report, error){
+		"zscore(0)":    func() (*anomaly.Report, error) { return anomaly.ZScore(s, 0, 3) },
+		"hampel(-1)":   func() (*anomaly.Report, error) { return anomaly.Hampel(s, -1, 3) },
+		"seasonal(0)":  func() (*anomaly.Report, error) { return anomaly.Seasonal(s, decompose.Periodic(0, timeserie.Day), 3) },
+		"seasonal(20)": func() (*anomaly.Report, error) { return anomaly.Seasonal(s, decompose.Periodic(20, timeserie.Day), 3) },
+		"cusum(0)":     func() (*anomaly.Report, error) { return anomaly.CUSUM(s, 0, 5, 0.5) },
+		"pelt(-1)":     func() (*anomaly.Report, error) { return anomaly.PELT(s, -1) },
+	} {
+		if _, err := detect(); err == nil {
+			t.Errorf("%s want error", name)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package bank imports bank statements from OFX and QIF files.
+//
+// A statement is loaded into series named after its account:
+//
+//	account             daily sum of the transactions
+//	account.balance     balance at the end of each day with transactions
+//	account.category.c  daily sum of the transactions, or split parts, in the category c
+//	account.transfer.x  daily sum of the transfers with the account x (QIF only)
+//
+// OFX statements have no categories, and their balances are computed backward from the ledger
+// balance. QIF statements have no balance, and their balances are the running sum of the
+// transactions, usually starting with an opening balance transaction.
+package bank
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// Transaction is a bank transaction.
+type Transaction struct {
+	Date     time.Time
+	Amount   float64
+	ID       string // Unique identifier given by the bank, if any.
+	Payee    string
+	Memo     string
+	Category string  // Category of the whole transaction, if it has no splits, "transfer.x" for a transfer with x.
+	Splits   []Split // Parts of the transaction in different categories.
+}
+
+// Split is a part of a transaction in a category.
+type Split struct {
+	Category string
+	Amount   float64
+	Memo     string
+}
+
+// Statement is a list of transactions of an account.
+type Statement struct {
+	Account      string
+	Currency     string // Currency code, if known.
+	Transactions []Transaction
+	Balance      float64   // Ledger balance, if BalanceOn is not zero.
+	BalanceOn    time.Time // Date of the ledger balance.
+}
+
+// Load adds the statement series to 'dict'. Transaction amounts are added to existing points at the
+// same date, and balances replace them.
+func (s *Statement) Load(dict map[string]*timeserie.Support) {
+	meta := timeserie.Meta{Name: s.Account, Unit: s.Currency}
+	sums := make(map[time.Time]float64) // daily sums of transactions.
+	for _, t := range s.Transactions {
+		day := t.Date.Truncate(timeserie.Day)
+		sums[day] += t.Amount
+		add(dict, s.Account, day, t.Amount, meta)
+		for _, sp := range t.Splits {
+			if sp.Category != "" {
+				add(dict, s.category(sp.Category), day, sp.Amount, timeserie.Meta{Name: sp.Category, Unit: s.Currency})
+			}
+		}
+		if len(t.Splits) == 0 && t.Category != "" {
+			add(dict, s.category(t.Category), day, t.Amount, timeserie.Meta{Name: t.Category, Unit: s.Currency})
+		}
+	}
+
+	// Balance at the end of each day is the cumulated sum shifted to match the ledger balance.
+	days := slices.SortedFunc(maps.Keys(sums), time.Time.Compare)
+	cum := make([]float64, len(days))
+	for i, d := range days {
+		cum[i] = sums[d]
+		if i > 0 {
+			cum[i] += cum[i-1]
+		}
+	}
+	offset := 0.0
+	if !s.BalanceOn.IsZero() {
+		on := s.BalanceOn.Truncate(timeserie.Day)
+		offset = s.Balance
+		for i, d := range days {
+			if !d.After(on) {
+				offset = s.Balance - cum[i]
+			}
+		}
+		if _, ok := sums[on]; !ok {
+			set(dict, s.Account+".balance", on, s.Balance, meta)
+		}
+	}
+	for i, d := range days {
+		set(dict, s.Account+".balance", d, cum[i]+offset, meta)
+	}
+}
+
+// category returns the name of the series of category 'c', in a namespace of its own so that
+// categories like "balance" do not overwrite other series.
+func (s *Statement) category(c string) string {
+	if strings.HasPrefix(c, transfer) {
+		return s.Account + "." + c
+	}
+	return s.Account + ".category." + c
+}
+
+// support returns the support 'name' in 'dict', created with 'meta' if needed.
+func support(dict map[string]*timeserie.Support, name string, meta timeserie.Meta) *timeserie.Support {
+	s, ok := dict[name]
+	if !ok {
+		s = new(timeserie.Support)
+		s.SetMeta(meta)
+		dict[name] = s
+	}
+	return s
+}
+
+// add adds 'v' to the point at 't', or appends it.
+func add(dict map[string]*timeserie.Support, name string, t time.Time, v float64, meta timeserie.Meta) {
+	s := support(dict, name, meta)
+	if i := s.Find(t); i >= 0 {
+		if on, old := s.At(i); on.Equal(t) {
+			v += old
+		}
+	}
+	s.Upsert(t, v)
+}
+
+// set sets the point at 't'.
+func set(dict map[string]*timeserie.Support, name string, t time.Time, v float64, meta timeserie.Meta) {
+	support(dict, name, meta).Upsert(t, v)
+}
@@ -0,0 +1,149 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// LoadOFX loads the statements of an OFX file into 'dict'.
+func LoadOFX(dict map[string]*timeserie.Support, r io.Reader) error {
+	list, err := ParseOFX(r)
+	if err != nil {
+		return err
+	}
+	for _, s := range list {
+		s.Load(dict)
+	}
+	return nil
+}
+
+// ParseOFX parses the bank and credit card statements of an OFX file, in the SGML (OFX 1.x) or the
+// XML (OFX 2.x) variant. Transactions repeated with the same identifier are kept once.
+func ParseOFX(r io.Reader) ([]Statement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read OFX: %w", err)
+	}
+	src := string(data)
+	i := strings.Index(strings.ToUpper(src), "<OFX>")
+	if i < 0 {
+		return nil, errors.New("invalid OFX: missing <OFX> element")
+	}
+	src = src[i:]
+
+	var list []Statement
+	var tx *Transaction
+	var ids map[string]bool // transaction identifiers of the current statement.
+	ledger := false         // within the ledger balance element.
+	for {
+		// SGML leaf elements have no closing tag: their value is the text up to the next tag.
+		lt := strings.IndexByte(src, '<')
+		if lt < 0 {
+			break
+		}
+		gt := strings.IndexByte(src[lt:], '>')
+		if gt < 0 {
+			return nil, errors.New("invalid OFX: unterminated tag")
+		}
+		tag := strings.ToUpper(strings.TrimSpace(src[lt+1 : lt+gt]))
+		src = src[lt+gt+1:]
+		end := strings.IndexByte(src, '<')
+		if end < 0 {
+			end = len(src)
+		}
+		value := strings.TrimSpace(html.UnescapeString(src[:end]))
+
+		if tag == "STMTRS" || tag == "CCSTMTRS" {
+			list = append(list, Statement{})
+			ids = make(map[string]bool)
+			continue
+		}
+		if len(list) == 0 {
+			continue
+		}
+		st := &list[len(list)-1]
+		switch tag {
+		case "CURDEF":
+			st.Currency = value
+		case "ACCTID":
+			st.Account = value
+		case "LEDGERBAL":
+			ledger = true
+		case "/LEDGERBAL":
+			ledger = false
+		case "BALAMT":
+			if ledger {
+				if st.Balance, err = ofxAmount(value); err != nil {
+					return nil, err
+				}
+			}
+		case "DTASOF":
+			if ledger {
+				if st.BalanceOn, err = ofxDate(value); err != nil {
+					return nil, err
+				}
+			}
+		case "STMTTRN":
+			tx = new(Transaction)
+		case "/STMTTRN":
+			if tx == nil {
+				continue
+			}
+			if tx.Date.IsZero() {
+				return nil, fmt.Errorf("invalid OFX: transaction %q has no date", tx.ID)
+			}
+			if tx.ID == "" || !ids[tx.ID] {
+				st.Transactions = append(st.Transactions, *tx)
+				ids[tx.ID] = true
+			}
+			tx = nil
+		}
+		if tx == nil {
+			continue
+		}
+		switch tag {
+		case "DTPOSTED":
+			tx.Date, err = ofxDate(value)
+		case "TRNAMT":
+			tx.Amount, err = ofxAmount(value)
+		case "FITID":
+			tx.ID = value
+		case "NAME":
+			tx.Payee = value
+		case "MEMO":
+			tx.Memo = value
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// ofxDate parses the date of an OFX datetime like "20240115120000.000[-5:EST]".
+func ofxDate(v string) (time.Time, error) {
+	if len(v) < 8 {
+		return time.Time{}, fmt.Errorf("invalid OFX date %q", v)
+	}
+	t, err := time.Parse("20060102", v[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid OFX date %q", v)
+	}
+	return t, nil
+}
+
+// ofxAmount parses an OFX amount, with a decimal point or comma.
+func ofxAmount(v string) (float64, error) {
+	a, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", "."), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid OFX amount %q", v)
+	}
+	return a, nil
+}
@@ -0,0 +1,84 @@
+package bank_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/bank"
+)
+
+// format returns the dump of 'dict'.
+func format(t *testing.T, dict map[string]*timeserie.Support) string {
+	t.Helper()
+	var b strings.Builder
+	if err := timeserie.Format(&b, dict); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+// open opens a test file.
+func open(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestLoadOFX(t *testing.T) {
+	for _, test := range []struct {
+		file string
+		want string
+	}{
+		{"testdata/checking.ofx", `{"meta":{"id":"FR7630004000031234567890143","name":"FR7630004000031234567890143","unit":"EUR"}}
+{"meta":{"id":"FR7630004000031234567890143.balance","name":"FR7630004000031234567890143","unit":"EUR"}}
+{ "on":"24-1-5", "FR7630004000031234567890143":-50, "FR7630004000031234567890143.balance":650}
+{ "on":"24-1-25", "FR7630004000031234567890143":2500, "FR7630004000031234567890143.balance":3150}
+{ "on":"24-1-31", "FR7630004000031234567890143.balance":3150}
+`},
+		{"testdata/card.ofx", `{"meta":{"id":"4111111111111111","name":"4111111111111111","unit":"USD"}}
+{"meta":{"id":"4111111111111111.balance","name":"4111111111111111","unit":"USD"}}
+{ "on":"24-2-10", "4111111111111111":-30, "4111111111111111.balance":-120}
+{ "on":"24-2-15", "4111111111111111":100, "4111111111111111.balance":-20}
+{ "on":"24-2-29", "4111111111111111.balance":-20}
+`},
+	} {
+		dict := make(map[string]*timeserie.Support)
+		if err := bank.LoadOFX(dict, open(t, test.file)); err != nil {
+			t.Errorf("LoadOFX(%s) error: %v", test.file, err)
+			continue
+		}
+		if got := format(t, dict); got != test.want {
+			t.Errorf("LoadOFX(%s) =\n%s\nwant\n%s", test.file, got, test.want)
+		}
+	}
+}
+
+func TestParseOFX(t *testing.T) {
+	list, err := bank.ParseOFX(open(t, "testdata/card.ofx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || len(list[0].Transactions) != 2 {
+		t.Fatalf("ParseOFX() = %+v, want 1 statement with 2 transactions", list)
+	}
+	if tx := list[0].Transactions[0]; tx.Payee != "Books & Co" || tx.ID != "A1" {
+		t.Errorf("ParseOFX() first transaction = %+v", tx)
+	}
+
+	for _, src := range []string{
+		"no ofx here",
+		"<OFX><STMTRS><STMTTRN><TRNAMT>12</STMTTRN></STMTRS></OFX>",
+		"<OFX><STMTRS><STMTTRN><DTPOSTED>2024<TRNAMT>12</STMTTRN></STMTRS></OFX>",
+		"<OFX><STMTRS><STMTTRN><DTPOSTED>20240101<TRNAMT>twelve</STMTTRN></STMTRS></OFX>",
+	} {
+		if _, err := bank.ParseOFX(strings.NewReader(src)); err == nil {
+			t.Errorf("ParseOFX(%q) succeeded, want an error", src)
+		}
+	}
+}
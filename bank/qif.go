@@ -0,0 +1,176 @@
+package bank
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// LoadQIF loads the statements of a QIF file into 'dict'. Transactions before any account
+// definition belong to 'account'.
+func LoadQIF(dict map[string]*timeserie.Support, r io.Reader, account string) error {
+	list, err := ParseQIF(r, account)
+	if err != nil {
+		return err
+	}
+	for _, s := range list {
+		s.Load(dict)
+	}
+	return nil
+}
+
+// qifTypes are the QIF account types holding bank transactions.
+var qifTypes = map[string]bool{"Bank": true, "Cash": true, "CCard": true, "Oth A": true, "Oth L": true}
+
+// ParseQIF parses the bank, cash and credit card transactions of a QIF file. Transactions before any
+// account definition belong to 'account'. Dates are read in the US order (month/day/year), or as
+// year-month-day.
+func ParseQIF(r io.Reader, account string) ([]Statement, error) {
+	var list []Statement
+	index := make(map[string]int) // statement index by account.
+	statement := func(account string) *Statement {
+		i, ok := index[account]
+		if !ok {
+			i = len(list)
+			index[account] = i
+			list = append(list, Statement{Account: account})
+		}
+		return &list[i]
+	}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	header := false // within an account definition.
+	name := ""      // name of the account being defined.
+	skip := true    // within a list of non bank items.
+	var tx Transaction
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		code, value := text[0], strings.TrimSpace(text[1:])
+		if code == '!' {
+			switch {
+			case value == "Account":
+				header, skip = true, true
+			case strings.HasPrefix(value, "Type:"):
+				header, skip = false, !qifTypes[strings.TrimPrefix(value, "Type:")]
+			}
+			continue
+		}
+		if header {
+			switch code {
+			case 'N':
+				name = value
+			case '^':
+				account = name
+			}
+			continue
+		}
+		if skip {
+			continue
+		}
+		var err error
+		switch code {
+		case 'D':
+			tx.Date, err = qifDate(value)
+		case 'T', 'U':
+			tx.Amount, err = qifAmount(value)
+		case 'P':
+			tx.Payee = value
+		case 'M':
+			tx.Memo = value
+		case 'L':
+			tx.Category = qifCategory(value)
+		case 'S':
+			tx.Splits = append(tx.Splits, Split{Category: qifCategory(value)})
+		case 'E':
+			if n := len(tx.Splits); n > 0 {
+				tx.Splits[n-1].Memo = value
+			}
+		case '$':
+			if n := len(tx.Splits); n > 0 {
+				tx.Splits[n-1].Amount, err = qifAmount(value)
+			}
+		case '^':
+			if tx.Date.IsZero() {
+				return nil, fmt.Errorf("invalid QIF line %v: transaction without date", line)
+			}
+			if tx.Category == transfer+account {
+				tx.Category = "" // opening balances are transfers from the account itself.
+			}
+			st := statement(account)
+			st.Transactions = append(st.Transactions, tx)
+			tx = Transaction{}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid QIF line %v %q: %w", line, text, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read QIF: %w", err)
+	}
+	return list, nil
+}
+
+// transfer prefixes the category of transfers with another account.
+const transfer = "transfer."
+
+// qifCategory returns the category without its class, like "Food" in "Food/Holidays". Transfers
+// with another account, like "[Savings]", are in the category "transfer.Savings".
+func qifCategory(v string) string {
+	c, _, _ := strings.Cut(v, "/")
+	if name, ok := strings.CutPrefix(c, "["); ok && strings.HasSuffix(name, "]") {
+		return transfer + strings.TrimSuffix(name, "]")
+	}
+	return c
+}
+
+// qifAmount parses an amount like "-1,234.56".
+func qifAmount(v string) (float64, error) {
+	a, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", v)
+	}
+	return a, nil
+}
+
+// qifDate parses dates like "1/15/2024", "01/15/24", "1/15'24", "01-15-2024" or "2024-01-15". Two
+// digits years are in 1970-2069, or 2000-2099 after an apostrophe.
+func qifDate(v string) (time.Time, error) {
+	apostrophe := strings.Contains(v, "'")
+	parts := strings.FieldsFunc(v, func(r rune) bool { return r == '/' || r == '-' || r == '\'' || r == '.' })
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", v)
+	}
+	n := make([]int, 3)
+	for i, p := range parts {
+		var err error
+		if n[i], err = strconv.Atoi(strings.TrimSpace(p)); err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", v)
+		}
+	}
+	year, month, day := n[2], n[0], n[1]
+	if len(parts[0]) == 4 {
+		year, month, day = n[0], n[1], n[2]
+	}
+	switch {
+	case year >= 100:
+	case apostrophe || year < 70:
+		year += 2000
+	default:
+		year += 1900
+	}
+	t := timeserie.DayDate(year, time.Month(month), day)
+	if month < 1 || month > 12 || day < 1 || day != t.Day() {
+		return time.Time{}, fmt.Errorf("invalid date %q", v)
+	}
+	return t, nil
+}
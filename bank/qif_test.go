@@ -0,0 +1,77 @@
+package bank_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/bank"
+)
+
+func TestLoadQIF(t *testing.T) {
+	dict := make(map[string]*timeserie.Support)
+	if err := bank.LoadQIF(dict, open(t, "testdata/accounts.qif"), "default"); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"meta":{"id":"Checking","name":"Checking"}}
+{"meta":{"id":"Checking.balance","name":"Checking"}}
+{"meta":{"id":"Checking.category.Food:Groceries","name":"Food:Groceries"}}
+{"meta":{"id":"Checking.category.Household","name":"Household"}}
+{"meta":{"id":"Checking.category.Rent","name":"Rent"}}
+{"meta":{"id":"Checking.transfer.Wallet","name":"transfer.Wallet"}}
+{"meta":{"id":"Wallet","name":"Wallet"}}
+{"meta":{"id":"Wallet.balance","name":"Wallet"}}
+{"meta":{"id":"Wallet.category.Food:Restaurants","name":"Food:Restaurants"}}
+{"meta":{"id":"Wallet.transfer.Checking","name":"transfer.Checking"}}
+{ "on":"24-1-1", "Checking":1000, "Checking.balance":1000}
+{ "on":"24-1-5", "Checking":-180, "Checking.balance":820, "Checking.category.Food:Groceries":-140, "Checking.category.Household":-40}
+{ "on":"24-1-6", "Wallet":-12.5, "Wallet.balance":-12.5, "Wallet.category.Food:Restaurants":-12.5}
+{ "on":"24-1-10", "Checking":-200, "Checking.balance":620, "Checking.transfer.Wallet":-200, "Wallet":200, "Wallet.balance":187.5, "Wallet.transfer.Checking":200}
+{ "on":"24-1-20", "Checking":-500, "Checking.balance":120, "Checking.category.Rent":-500}
+`
+	if got := format(t, dict); got != want {
+		t.Errorf("LoadQIF() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestLoadQIF_balance checks that a category named "balance" does not overwrite the balance.
+func TestLoadQIF_balance(t *testing.T) {
+	dict := make(map[string]*timeserie.Support)
+	if err := bank.LoadQIF(dict, strings.NewReader("!Type:Bank\nD1/2/2024\nT-3\nLbalance\n^\n"), "main"); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"meta":{"id":"main","name":"main"}}
+{"meta":{"id":"main.balance","name":"main"}}
+{"meta":{"id":"main.category.balance","name":"balance"}}
+{ "on":"24-1-2", "main":-3, "main.balance":-3, "main.category.balance":-3}
+`
+	if got := format(t, dict); got != want {
+		t.Errorf("LoadQIF() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseQIF(t *testing.T) {
+	list, err := bank.ParseQIF(strings.NewReader("!Type:Bank\nD12/31'99\nT10\n^\nD12/31/99\nT5\n^\n"), "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Account != "main" || len(list[0].Transactions) != 2 {
+		t.Fatalf("ParseQIF() = %+v, want 2 transactions in 'main'", list)
+	}
+	if y := list[0].Transactions[0].Date.Year(); y != 2099 {
+		t.Errorf("ParseQIF() year after an apostrophe = %d, want 2099", y)
+	}
+	if y := list[0].Transactions[1].Date.Year(); y != 1999 {
+		t.Errorf("ParseQIF() year = %d, want 1999", y)
+	}
+
+	for _, src := range []string{
+		"!Type:Bank\nT10\n^\n",
+		"!Type:Bank\nD2/30/2024\nT10\n^\n",
+		"!Type:Bank\nD1/1/2024\nTten\n^\n",
+	} {
+		if _, err := bank.ParseQIF(strings.NewReader(src), "main"); err == nil {
+			t.Errorf("ParseQIF(%q) succeeded, want an error", src)
+		}
+	}
+}
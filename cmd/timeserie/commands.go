@@ -0,0 +1,217 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/httpapi"
+	"github.com/etnz/timeserie/plot"
+)
+
+// commands are all the available commands.
+var commands = []command{
+	{"cat", "merge dumps into a single dump", cat},
+	{"ls", "list series with their number of points and date range", ls},
+	{"slice", "keep points in a date range", slice},
+	{"resample", "sample series at the start of each calendar period", resample},
+	{"stats", "print statistics of each series", stats},
+	{"convert", "convert dumps to another format", convert},
+	{"plot", "plot series in the terminal", plotCmd},
+	{"serve", "serve series over HTTP", serve},
+}
+
+func cat(fs *flag.FlagSet, args []string, in io.Reader, out io.Writer) error {
+	dict, err := input(fs)(args, in)
+	if err != nil {
+		return err
+	}
+	return timeserie.Format(out, dict)
+}
+
+func ls(fs *flag.FlagSet, args []string, in io.Reader, out io.Writer) error {
+	dict, err := input(fs)(args, in)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "name\tcount\tfrom\tto")
+	for _, id := range slices.Sorted(maps.Keys(dict)) {
+		s := dict[id]
+		from, to := bounds(s)
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", id, s.Len(), from, to)
+	}
+	return w.Flush()
+}
+
+// bounds returns the dates of the first and last points of 's'.
+func bounds(s *timeserie.Support) (from, to string) {
+	if s.Len() == 0 {
+		return "-", "-"
+	}
+	first, _ := s.At(0)
+	last, _ := s.At(s.Len() - 1)
+	return first.Format(time.DateOnly), last.Format(time.DateOnly)
+}
+
+// dateFlag is a flag holding an optional date.
+type dateFlag struct{ time.Time }
+
+func (d *dateFlag) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(time.DateOnly)
+}
+
+func (d *dateFlag) Set(s string) (err error) {
+	d.Time, err = time.Parse(time.DateOnly, s)
+	return err
+}
+
+func slice(fs *flag.FlagSet, args []string, in io.Reader, out io.Writer) error {
+	from, to := new(dateFlag), new(dateFlag)
+	fs.Var(from, "from", "first date to keep, as YYYY-MM-DD (default: no limit)")
+	fs.Var(to, "to", "first date to drop, as YYYY-MM-DD (default: no limit)")
+	dict, err := input(fs)(args, in)
+	if err != nil {
+		return err
+	}
+	end := to.Time
+	if end.IsZero() {
+		end = time.Unix(1<<62, 0) // far in the future.
+	}
+	for id, s := range dict {
+		dict[id] = s.Slice(from.Time, end)
+	}
+	return timeserie.Format(out, dict)
+}
+
+// modes are the function modes accepted by flags.
+var modes = map[string]timeserie.Mode{
+	"nullset": timeserie.ModeNullset,
+	"step":    timeserie.ModeStep,
+	"linear":  timeserie.ModeLinear,
+}
+
+func resample(fs *flag.FlagSet, args []string, in io.Reader, out io.Writer) error {
+	period := fs.String("period", "month", "sampling period: day, week, month, quarter or year")
+	mode := fs.String("mode", "step", "function mode: nullset, step or linear")
+	dict, err := input(fs)(args, in)
+	if err != nil {
+		return err
+	}
+	cond, ok := timeserie.Periods[*period]
+	if !ok {
+		return fmt.Errorf("resample: unknown period %q", *period)
+	}
+	m, ok := modes[*mode]
+	if !ok {
+		return fmt.Errorf("resample: unknown mode %q", *mode)
+	}
+	for id, s := range dict {
+		if s.Len() == 0 {
+			continue
+		}
+		first, _ := s.At(0)
+		last, _ := s.At(s.Len() - 1)
+		days := timeserie.Days(first, last.Add(timeserie.Day), cond)
+		dict[id] = &timeserie.Sample(days, timeserie.New(s, m)).Support
+	}
+	return timeserie.Format(out, dict)
+}
+
+func stats(fs *flag.FlagSet, args []string, in io.Reader, out io.Writer) error {
+	dict, err := input(fs)(args, in)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "name\tcount\tmin\tmax\tmean\tstddev\tsum\t")
+	for _, id := range slices.Sorted(maps.Keys(dict)) {
+		s := dict[id]
+		lo, hi, sum, sum2 := math.Inf(1), math.Inf(-1), 0.0, 0.0
+		for _, v := range s.Values() {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+			sum += v
+			sum2 += v * v
+		}
+		n := float64(s.Len())
+		mean := sum / n
+		sd := math.Sqrt(math.Max(0, sum2/n-mean*mean))
+		fmt.Fprintf(w, "%s\t%d\t%g\t%g\t%.6g\t%.6g\t%g\t\n", id, s.Len(), lo, hi, mean, sd, sum)
+	}
+	return w.Flush()
+}
+
+func convert(fs *flag.FlagSet, args []string, in io.Reader, out io.Writer) error {
+	to := fs.String("o", "jsonl", "output format: jsonl, csv, bin or lp")
+	dict, err := input(fs)(args, in)
+	if err != nil {
+		return err
+	}
+	return format(out, dict, *to, fs.Lookup("scheme").Value.String())
+}
+
+func plotCmd(fs *flag.FlagSet, args []string, in io.Reader, out io.Writer) error {
+	width := fs.Int("width", 60, "chart width in characters")
+	height := fs.Int("height", 8, "chart height in lines, for ascii and braille charts")
+	chart := fs.String("chart", "spark", "chart kind: spark, ascii or braille")
+	mode := fs.String("mode", "linear", "function mode: nullset, step or linear")
+	dict, err := input(fs)(args, in)
+	if err != nil {
+		return err
+	}
+	m, ok := modes[*mode]
+	if !ok {
+		return fmt.Errorf("plot: unknown mode %q", *mode)
+	}
+	ids := slices.Sorted(maps.Keys(dict))
+	pad := 0
+	for _, id := range ids {
+		pad = max(pad, len(id))
+	}
+	for _, id := range ids {
+		f := timeserie.New(dict[id], m)
+		switch *chart {
+		case "spark":
+			from, to := bounds(dict[id])
+			fmt.Fprintf(out, "%-*s %s %s %s\n", pad, id, from, plot.Sparkline(f, *width), to)
+		case "ascii":
+			fmt.Fprintf(out, "%s\n%s", id, plot.ASCII(f, *width, *height))
+		case "braille":
+			fmt.Fprintf(out, "%s\n%s", id, plot.Braille(f, *width, *height))
+		default:
+			return fmt.Errorf("plot: unknown chart %q", *chart)
+		}
+	}
+	return nil
+}
+
+func serve(fs *flag.FlagSet, args []string, in io.Reader, out io.Writer) error {
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	store := fs.String("store", "", "jsonline dump where posted records are appended, loaded first if it exists")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("serve: %w", err)
+	}
+	files := fs.Args()
+	if *store != "" {
+		if _, err := os.Stat(*store); err == nil {
+			files = append([]string{*store}, files...)
+		}
+	}
+	dict, err := timeserie.Open(make(map[string]*timeserie.Support), files...)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "serving %d series on http://%s\n", len(dict), *addr)
+	return http.ListenAndServe(*addr, httpapi.New(dict, *store))
+}
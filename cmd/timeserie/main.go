@@ -0,0 +1,176 @@
+// Command timeserie manipulates value change dumps.
+//
+// Usage:
+//
+//	timeserie <command> [flags] [files...]
+//
+// Commands read the files, or the standard input if there are none, and write to the standard output.
+// Files are read according to their extension: ".csv" for CSV, ".bin" for binary, ".lp" for InfluxDB
+// line protocol, ".ofx" and ".qif" for bank statements, and the jsonline value change dump otherwise.
+// The '-i' flag sets the format of the standard input, and overrides extensions.
+//
+// Line protocol fields are series named according to the '-scheme' flag, which also splits series
+// names back when writing line protocol:
+//
+//	field        'field', merging fields of different measurements or tags (default)
+//	measurement  'measurement.field', merging fields of different tags
+//	tags         'measurement.value1.value2.field', that cannot be written back
+//	series       'measurement,key1=value1,key2=value2 field', as in a line
+//
+// The commands are:
+//
+//	cat       merge dumps into a single dump
+//	ls        list series with their number of points and date range
+//	slice     keep points in a date range
+//	resample  sample series at the start of each calendar period
+//	stats     print statistics of each series
+//	convert   convert dumps to another format
+//	plot      plot series in the terminal
+//	serve     serve series over HTTP (see package httpapi)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/bank"
+	"github.com/etnz/timeserie/influx"
+)
+
+// command is a subcommand: it declares its flags in 'fs', parses 'args' and runs.
+type command struct {
+	name, usage string
+	run         func(fs *flag.FlagSet, args []string, in io.Reader, out io.Writer) error
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "timeserie:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command line 'args' on 'in' and 'out'.
+func run(args []string, in io.Reader, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", usage())
+	}
+	i := slices.IndexFunc(commands, func(c command) bool { return c.name == args[0] })
+	if i < 0 {
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage())
+	}
+	c := commands[i]
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return c.run(fs, args[1:], in, out)
+}
+
+// usage returns the list of commands.
+func usage() string {
+	var b strings.Builder
+	b.WriteString("usage: timeserie <command> [flags] [files...]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "  %-9s %s\n", c.name, c.usage)
+	}
+	return b.String()
+}
+
+// input declares the '-i' flag and returns a function that parses 'args' and loads the dumps.
+func input(fs *flag.FlagSet) func(args []string, in io.Reader) (map[string]*timeserie.Support, error) {
+	format := fs.String("i", "", "input format: jsonl, csv, bin, lp, ofx or qif (default from extension, jsonl for stdin)")
+	scheme := fs.String("scheme", "field", "line protocol naming scheme: field, measurement, tags or series")
+	return func(args []string, in io.Reader) (map[string]*timeserie.Support, error) {
+		if err := fs.Parse(args); err != nil {
+			var b strings.Builder
+			fs.SetOutput(&b)
+			fs.PrintDefaults()
+			return nil, fmt.Errorf("%s: %w\nusage: timeserie %s [flags] [files...]\n%s", fs.Name(), err, fs.Name(), b.String())
+		}
+		dict := make(map[string]*timeserie.Support)
+		if fs.NArg() == 0 {
+			return dict, load(dict, in, *format, *scheme, "")
+		}
+		for _, name := range fs.Args() {
+			f, err := os.Open(name)
+			if err != nil {
+				return nil, fmt.Errorf("cannot open file %q: %w", name, err)
+			}
+			err = load(dict, f, *format, *scheme, name)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("cannot read %q content: %w", name, err)
+			}
+		}
+		return dict, nil
+	}
+}
+
+// load reads 'r' into 'dict' in 'format', or the format given by the extension of 'name'. Unknown
+// extensions are read as jsonl, but an unknown 'format' is an error. Line protocol is read with the
+// naming 'scheme'.
+func load(dict map[string]*timeserie.Support, r io.Reader, format, scheme, name string) error {
+	explicit := format != ""
+	if !explicit {
+		format = strings.TrimPrefix(filepath.Ext(name), ".")
+	}
+	switch format {
+	case "jsonl":
+		return timeserie.Load(dict, r)
+	case "csv":
+		return timeserie.LoadCSV(dict, r)
+	case "bin":
+		return timeserie.LoadBinary(dict, r)
+	case "lp":
+		sc, ok := schemes[scheme]
+		if !ok {
+			return fmt.Errorf("unknown line protocol scheme %q", scheme)
+		}
+		return influx.Load(dict, r, sc, time.Now())
+	case "ofx":
+		return bank.LoadOFX(dict, r)
+	case "qif":
+		account := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+		if name == "" {
+			account = "qif"
+		}
+		return bank.LoadQIF(dict, r, account)
+	}
+	if explicit {
+		return fmt.Errorf("unknown input format %q", format)
+	}
+	return timeserie.Load(dict, r)
+}
+
+// schemes are the line protocol naming schemes by name.
+var schemes = map[string]influx.Scheme{
+	"field":       influx.SchemeField("timeserie"),
+	"measurement": influx.SchemeMeasurement,
+	"tags":        influx.SchemeTags,
+	"series":      influx.SchemeSeries,
+}
+
+// format writes 'dict' to 'w' in 'format', line protocol being written with the naming 'scheme'.
+func format(w io.Writer, dict map[string]*timeserie.Support, format, scheme string) error {
+	switch format {
+	case "jsonl", "":
+		return timeserie.Format(w, dict)
+	case "csv":
+		return timeserie.FormatCSV(w, dict)
+	case "bin":
+		return timeserie.FormatBinary(w, dict)
+	case "lp":
+		sc, ok := schemes[scheme]
+		if !ok {
+			return fmt.Errorf("unknown line protocol scheme %q", scheme)
+		}
+		return influx.Format(w, dict, sc)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
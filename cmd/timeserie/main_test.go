@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const dump = `{"on":"24-1-1","a":1,"b":2}
+{"on":"24-1-15","a":3}
+{"on":"24-2-3","a":5,"b":1}
+`
+
+// TestRun checks each command output on a small dump read from stdin.
+func TestRun(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"cat"}, "{ \"on\":\"24-1-1\", \"a\":1, \"b\":2}\n{ \"on\":\"24-1-15\", \"a\":3}\n{ \"on\":\"24-2-3\", \"a\":5, \"b\":1}\n"},
+		{[]string{"ls"}, "name  count  from        to\na     3      2024-01-01  2024-02-03\nb     2      2024-01-01  2024-02-03\n"},
+		{[]string{"slice", "-from", "2024-01-10", "-to", "2024-02-01"}, "{ \"on\":\"24-1-15\", \"a\":3}\n"},
+		{[]string{"resample", "-period", "month"}, "{ \"on\":\"24-1-1\", \"a\":1, \"b\":2}\n{ \"on\":\"24-2-1\", \"a\":3, \"b\":2}\n"},
+		{[]string{"convert", "-o", "csv"}, "on,a,b\n24-1-1,1,2\n24-1-15,3,\n24-2-3,5,1\n"},
+		{[]string{"convert", "-o", "lp"}, "timeserie a=1,b=2 1704067200000000000\ntimeserie a=3 1705276800000000000\ntimeserie a=5,b=1 1706918400000000000\n"},
+		{[]string{"plot", "-width", "4", "-mode", "nullset"}, "a 2024-01-01 ▁▅ █ 2024-02-03\nb 2024-01-01 █  ▁ 2024-02-03\n"},
+	} {
+		var out bytes.Buffer
+		if err := run(test.args, strings.NewReader(dump), &out); err != nil {
+			t.Errorf("run(%q) error: %v", test.args, err)
+			continue
+		}
+		if out.String() != test.want {
+			t.Errorf("run(%q) =\n%s\nwant\n%s", test.args, out.String(), test.want)
+		}
+	}
+}
+
+// TestRun_files checks that files are read according to their extension.
+func TestRun_files(t *testing.T) {
+	dir := t.TempDir()
+	jsonl := filepath.Join(dir, "d.jsonl")
+	if err := os.WriteFile(jsonl, []byte(dump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var bin bytes.Buffer
+	if err := run([]string{"convert", "-o", "bin", jsonl}, nil, &bin); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(dir, "d.bin")
+	if err := os.WriteFile(binary, bin.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := run([]string{"cat", binary}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), `{ "on":"24-1-1", "a":1, "b":2}`) {
+		t.Errorf("cat d.bin = %q", out.String())
+	}
+
+	var lp bytes.Buffer
+	if err := run([]string{"convert", "-o", "lp", jsonl}, nil, &lp); err != nil {
+		t.Fatal(err)
+	}
+	protocol := filepath.Join(dir, "d.lp")
+	if err := os.WriteFile(protocol, lp.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := run([]string{"cat", protocol}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{ \"on\":\"24-1-1\", \"a\":1, \"b\":2}\n{ \"on\":\"24-1-15\", \"a\":3}\n{ \"on\":\"24-2-3\", \"a\":5, \"b\":1}\n"; out.String() != want {
+		t.Errorf("cat d.lp =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// series of different measurements and tags are kept apart, and written back, with -scheme series.
+	lines := "cpu,host=a usage=1 1704067200000000000\nmem,host=a usage=2 1704067200000000000\nmem,host=b usage=3 1704067200000000000\n"
+	if err := os.WriteFile(protocol, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := run([]string{"convert", "-scheme", "series", "-o", "lp", protocol}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != lines {
+		t.Errorf("convert -scheme series -o lp d.lp =\n%s\nwant\n%s", out.String(), lines)
+	}
+
+	qif := filepath.Join(dir, "wallet.qif")
+	if err := os.WriteFile(qif, []byte("!Type:Cash\nD1/2/2024\nT-3\nLFood\n^\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := run([]string{"ls", qif}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "wallet.category.Food"; !strings.Contains(out.String(), want) {
+		t.Errorf("ls wallet.qif = %q, want series %q", out.String(), want)
+	}
+}
+
+// TestRun_errors checks unknown commands, flags and input formats.
+func TestRun_errors(t *testing.T) {
+	for _, args := range [][]string{{}, {"nope"}, {"cat", "-nope"}, {"resample", "-period", "nope"}, {"cat", "-i", "nope"}, {"convert", "-o", "lp", "-scheme", "nope"}} {
+		if err := run(args, strings.NewReader(dump), new(bytes.Buffer)); err == nil {
+			t.Errorf("run(%q) error = nil want error", args)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package timeserie
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Here goes functions to move series in time, and to compute correlations.
+
+// Lag returns a new function whose support is moved by 'd' in time, so that Lag(f, d).F(t) is f.F(t-d).
+func Lag(f *Function, d time.Duration) *Function {
+	s := new(Support)
+	for on, v := range f.Values() {
+		s.Append(on.Add(d), v)
+	}
+	return New(s, f.mode)
+}
+
+// Shift returns a new support with the same times, where the point at index i takes the value of the
+// point at index i-n. Points without a value are dropped.
+func (s *Support) Shift(n int) *Support {
+	res := new(Support)
+	for i, on := range s.times {
+		if j := i - n; j >= 0 && j < len(s.values) {
+			res.Append(on, s.values[j])
+		}
+	}
+	return res
+}
+
+// ACF returns the sample autocorrelations of 's', from lag 0 to 'maxLag'.
+//
+// Lags are counted in points: the spacing of the support is ignored, so it is usually regular. A
+// constant support has an autocorrelation of 1 at lag 0 and 0 elsewhere. An error is returned if
+// 'maxLag' is negative.
+func ACF(s *Support, maxLag int) ([]float64, error) { return Autocorrelations(s.values, maxLag) }
+
+// PACF returns the sample partial autocorrelations of 's', from lag 1 to 'maxLag'.
+//
+// Lags are counted in points, like in ACF. An error is returned if 'maxLag' is negative.
+func PACF(s *Support, maxLag int) ([]float64, error) {
+	acf, err := ACF(s, maxLag)
+	if err != nil {
+		return nil, err
+	}
+	_, pacf, err := YuleWalker(acf)
+	return pacf, err
+}
+
+// Autocorrelations returns the sample autocorrelations of 'x' from lag 0 to 'maxLag'.
+//
+// A constant 'x' has an autocorrelation of 1 at lag 0 and 0 elsewhere. An error is returned if
+// 'maxLag' is negative.
+func Autocorrelations(x []float64, maxLag int) ([]float64, error) {
+	if maxLag < 0 {
+		return nil, fmt.Errorf("autocorrelations require a non-negative lag got %v", maxLag)
+	}
+	mu := mean(x)
+	acov := make([]float64, maxLag+1)
+	for k := range acov {
+		for t := k; t < len(x); t++ {
+			acov[k] += (x[t] - mu) * (x[t-k] - mu)
+		}
+	}
+	acf := make([]float64, len(acov))
+	for k, c := range acov {
+		switch {
+		case acov[0] != 0:
+			acf[k] = c / acov[0]
+		case k == 0 && len(x) > 0:
+			acf[k] = 1
+		case len(x) == 0:
+			acf[k] = math.NaN()
+		}
+	}
+	return acf, nil
+}
+
+// YuleWalker solves the Yule-Walker equations for the autocorrelations 'acf' from lag 0 to p, using
+// the Levinson-Durbin recursion. It returns the p AR coefficients and the p partial
+// autocorrelations.
+//
+// An error is returned if 'acf' is empty, or if it is not finite, like the autocorrelations of an
+// empty series.
+func YuleWalker(acf []float64) (phi, pacf []float64, err error) {
+	if len(acf) == 0 {
+		return nil, nil, fmt.Errorf("Yule-Walker requires the autocorrelation at lag 0")
+	}
+	if slices.ContainsFunc(acf, func(v float64) bool { return math.IsNaN(v) || math.IsInf(v, 0) }) {
+		return nil, nil, fmt.Errorf("Yule-Walker requires finite autocorrelations got %v", acf)
+	}
+	p := len(acf) - 1
+	pacf = make([]float64, p)
+	phi = make([]float64, p)
+	v := 1.0
+	for k := 0; k < p && v > 0; k++ {
+		a := acf[k+1]
+		for j := 0; j < k; j++ {
+			a -= phi[j] * acf[k-j]
+		}
+		a /= v
+		next := make([]float64, p)
+		copy(next, phi)
+		next[k] = a
+		for j := 0; j < k; j++ {
+			next[j] = phi[j] - a*phi[k-1-j]
+		}
+		phi, pacf[k] = next, a
+		v *= 1 - a*a
+	}
+	return phi, pacf, nil
+}
+
+// CrossCorrelation returns the sample cross-correlations between 'a' and 'b' from lag -'maxLag' to
+// 'maxLag'. The value at index maxLag+k is the correlation between a[t] and b[t+k], so that a peak
+// at a positive lag means 'a' leads 'b'.
+//
+// Functions are aligned on the times returned by Iterate, where both are defined, and lags are
+// counted in those times. An error is returned if 'maxLag' is negative.
+func CrossCorrelation(a, b *Function, maxLag int) ([]float64, error) {
+	if maxLag < 0 {
+		return nil, fmt.Errorf("cross-correlation requires a non-negative lag got %v", maxLag)
+	}
+	var x, y []float64
+	for on := range Iterate(a, b) {
+		u, v := a.F(on), b.F(on)
+		if math.IsNaN(u) || math.IsNaN(v) {
+			continue
+		}
+		x, y = append(x, u), append(y, v)
+	}
+	mx, my := mean(x), mean(y)
+	var sx, sy float64
+	for i := range x {
+		sx += (x[i] - mx) * (x[i] - mx)
+		sy += (y[i] - my) * (y[i] - my)
+	}
+	norm := math.Sqrt(sx * sy)
+	res := make([]float64, 2*maxLag+1)
+	for k := -maxLag; k <= maxLag; k++ {
+		var c float64
+		for t := max(0, -k); t < len(x) && t+k < len(y); t++ {
+			c += (x[t] - mx) * (y[t+k] - my)
+		}
+		res[maxLag+k] = c / norm
+	}
+	return res, nil
+}
+
+// mean returns the arithmetic mean of 'x'.
+func mean(x []float64) float64 {
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
+
+// Median returns the median of 'x', NaN if empty.
+func Median(x []float64) float64 {
+	sorted := slices.Sorted(slices.Values(x))
+	n := len(sorted)
+	if n == 0 {
+		return math.NaN()
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
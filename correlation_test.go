@@ -0,0 +1,129 @@
+package timeserie_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/etnz/timeserie"
+)
+
+// TestLag checks that the support is moved in time.
+func TestLag(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(d0, 1.0)
+	s.Append(d1, 2.0)
+	f := timeserie.Lag(timeserie.New(s, timeserie.ModeNullset), timeserie.Day)
+	if f.F(d1) != 1.0 || f.F(d2) != 2.0 || !math.IsNaN(f.F(d0)) {
+		t.Errorf("Lag(1 day) = %v, %v, %v want NaN, 1, 2", f.F(d0), f.F(d1), f.F(d2))
+	}
+}
+
+// TestSupport_Shift checks shifting by index in both directions.
+func TestSupport_Shift(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(d0, 1.0)
+	s.Append(d1, 2.0)
+	s.Append(d2, 3.0)
+
+	x := s.Shift(1)
+	if x.Len() != 2 {
+		t.Fatalf("Shift(1).Len() = %v want 2", x.Len())
+	}
+	if on, v := x.At(0); on != d1 || v != 1.0 {
+		t.Errorf("Shift(1).At(0) = %v, %v want %v, 1", on, v, d1)
+	}
+	x = s.Shift(-1)
+	if on, v := x.At(0); on != d0 || v != 2.0 {
+		t.Errorf("Shift(-1).At(0) = %v, %v want %v, 2", on, v, d0)
+	}
+}
+
+// TestACF checks autocorrelations of an alternating series.
+func TestACF(t *testing.T) {
+	s := new(timeserie.Support)
+	for i := 0; i < 100; i++ {
+		s.Append(timeserie.DayDate(2000, 1, 1+i), float64(i%2))
+	}
+	acf, err := timeserie.ACF(s, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acf[0] != 1 || math.Abs(acf[1]+1) > 0.02 || math.Abs(acf[2]-1) > 0.03 {
+		t.Errorf("ACF() = %v want [1 -1 1]", acf)
+	}
+	pacf, err := timeserie.PACF(s, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(pacf[0]+1) > 0.02 {
+		t.Errorf("PACF()[0] = %v want -1", pacf[0])
+	}
+}
+
+// TestACF_constant checks that the autocorrelations of a constant series are defined.
+func TestACF_constant(t *testing.T) {
+	s := new(timeserie.Support)
+	for i := 0; i < 10; i++ {
+		s.Append(timeserie.DayDate(2000, 1, 1+i), 3)
+	}
+	if acf, _ := timeserie.ACF(s, 2); !slices.Equal(acf, []float64{1, 0, 0}) {
+		t.Errorf("ACF(constant) = %v want [1 0 0]", acf)
+	}
+	if pacf, _ := timeserie.PACF(s, 2); !slices.Equal(pacf, []float64{0, 0}) {
+		t.Errorf("PACF(constant) = %v want [0 0]", pacf)
+	}
+}
+
+// TestACF_negativeLag checks that negative lags are rejected instead of panicking.
+func TestACF_negativeLag(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(timeserie.DayDate(2000, 1, 1), 1)
+	f := timeserie.New(s, timeserie.ModeNullset)
+	for name, err := range map[string]error{
+		"ACF":              second(timeserie.ACF(s, -2)),
+		"PACF":             second(timeserie.PACF(s, -2)),
+		"CrossCorrelation": second(timeserie.CrossCorrelation(f, f, -1)),
+	} {
+		if err == nil {
+			t.Errorf("%s(negative lag) want error", name)
+		}
+	}
+}
+
+// TestYuleWalker checks the AR coefficients of an AR(1) autocorrelation function.
+func TestYuleWalker(t *testing.T) {
+	phi, pacf, err := timeserie.YuleWalker([]float64{1, 0.5, 0.25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(phi[0]-0.5) > 1e-12 || math.Abs(phi[1]) > 1e-12 {
+		t.Errorf("YuleWalker() phi = %v want [0.5 0]", phi)
+	}
+	if math.Abs(pacf[0]-0.5) > 1e-12 || math.Abs(pacf[1]) > 1e-12 {
+		t.Errorf("YuleWalker() pacf = %v want [0.5 0]", pacf)
+	}
+	for _, acf := range [][]float64{nil, {math.NaN(), math.NaN()}} {
+		if _, _, err := timeserie.YuleWalker(acf); err == nil {
+			t.Errorf("YuleWalker(%v) want error", acf)
+		}
+	}
+}
+
+// TestCrossCorrelation checks that the lead is found.
+func TestCrossCorrelation(t *testing.T) {
+	a := new(timeserie.Support)
+	for i := 0; i < 100; i++ {
+		a.Append(timeserie.DayDate(2000, 1, 1+i), math.Sin(float64(i*i)))
+	}
+	fa := timeserie.New(a, timeserie.ModeNullset)
+	fb := timeserie.Lag(fa, 2*timeserie.Day)
+
+	cc, err := timeserie.CrossCorrelation(fa, fb, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i := slices.Index(cc, slices.Max(cc)); i != 3+2 {
+		t.Errorf("CrossCorrelation() = %v want max at lag 2", cc)
+	}
+}
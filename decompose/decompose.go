@@ -0,0 +1,209 @@
+// Package decompose splits timeserie Supports into trend, seasonal and residual components.
+package decompose
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// Season describes the seasonality of a regularly spaced support.
+type Season struct {
+	Step     time.Duration         // Spacing between points.
+	Period   int                   // Number of points in a season.
+	Position func(t time.Time) int // Position of a time in the season, in [0, Period).
+}
+
+var (
+	// Weekly is the seasonality of a week on daily supports.
+	Weekly = Season{Step: timeserie.Day, Period: 7, Position: func(t time.Time) int { return int(t.Weekday()) }}
+
+	// Yearly is the seasonality of a year on daily supports. February 29 shares the position of
+	// February 28, so that all years have the same number of positions.
+	Yearly = Season{Step: timeserie.Day, Period: 365, Position: func(t time.Time) int {
+		_, m, d := t.Date()
+		if m == time.February && d == 29 {
+			d = 28
+		}
+		return timeserie.DayDate(2001, m, d).YearDay() - 1
+	}}
+)
+
+// Periodic returns the seasonality of 'period' points spaced by 'step'. Positions are counted from
+// the Unix epoch.
+func Periodic(period int, step time.Duration) Season {
+	return Season{Step: step, Period: period, Position: func(t time.Time) int {
+		i := int(t.Sub(time.Unix(0, 0)) / step)
+		return ((i % period) + period) % period
+	}}
+}
+
+// Model is the way components are combined.
+type Model int
+
+const (
+	Additive       Model = iota // Values are Trend + Seasonal + Residual.
+	Multiplicative              // Values are Trend * Seasonal * Residual.
+)
+
+// Decomposition is the result of a decomposition.
+//
+// Components are defined on the times of the decomposed support, except where they cannot be
+// computed (e.g. the trend of a classical decomposition on the first and last half season).
+type Decomposition struct {
+	Trend, Seasonal, Residual *timeserie.Support
+}
+
+// regular is a support sampled at every step, with missing points linearly interpolated.
+type regular struct {
+	times    []time.Time
+	values   []float64
+	original []bool // true if the point was in the support.
+}
+
+// regularize returns the support 's' sampled at every step of 'season'.
+func regularize(s *timeserie.Support, season Season) (*regular, error) {
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("cannot decompose invalid support: %w", err)
+	}
+	if s.Len() < 2*season.Period {
+		return nil, fmt.Errorf("decomposition requires at least two seasons of %v points got %v", season.Period, s.Len())
+	}
+	first, _ := s.At(0)
+	last, _ := s.At(s.Len() - 1)
+	times := timeserie.Every(first, last.Add(season.Step), season.Step)
+	filled := timeserie.New(timeserie.Fill(s, times, timeserie.FillLinear, 0), timeserie.ModeNullset)
+	original := timeserie.New(s, timeserie.ModeNullset)
+	r := &regular{times: times}
+	for _, t := range times {
+		v := filled.F(t)
+		if math.IsNaN(v) {
+			return nil, fmt.Errorf("cannot decompose support: %v is not aligned on steps of %v from %v", t, season.Step, first)
+		}
+		r.values = append(r.values, v)
+		r.original = append(r.original, !math.IsNaN(original.F(t)))
+	}
+	return r, nil
+}
+
+// refill replaces the interpolated points by the fitted values, where the trend is defined.
+func (r *regular) refill(trend, seasonal []float64, model Model) {
+	for i, ok := range r.original {
+		if ok || math.IsNaN(trend[i]) {
+			continue
+		}
+		if model == Multiplicative {
+			r.values[i] = trend[i] * seasonal[i]
+		} else {
+			r.values[i] = trend[i] + seasonal[i]
+		}
+	}
+}
+
+// refills is the number of times missing points are refilled with fitted values.
+const refills = 5
+
+// decomposition returns the components at the original times.
+func (r *regular) decomposition(trend, seasonal, residual []float64) *Decomposition {
+	d := &Decomposition{Trend: new(timeserie.Support), Seasonal: new(timeserie.Support), Residual: new(timeserie.Support)}
+	for i, t := range r.times {
+		if !r.original[i] {
+			continue
+		}
+		d.Trend.Append(t, trend[i])
+		d.Seasonal.Append(t, seasonal[i])
+		d.Residual.Append(t, residual[i])
+	}
+	return d
+}
+
+// Classical returns the classical decomposition of 's': the trend is a centered moving average over a
+// season, and the seasonal component is the average detrended value at each position.
+//
+// Missing points are linearly interpolated, then refilled with the fitted values for the computation.
+func Classical(s *timeserie.Support, season Season, model Model) (*Decomposition, error) {
+	r, err := regularize(s, season)
+	if err != nil {
+		return nil, err
+	}
+	var trend, seasonal []float64
+	for range refills {
+		trend, seasonal = classical(r, season, model)
+		r.refill(trend, seasonal, model)
+	}
+	residual := make([]float64, len(r.values))
+	for i, v := range r.values {
+		if model == Multiplicative {
+			residual[i] = v / (trend[i] * seasonal[i])
+		} else {
+			residual[i] = v - trend[i] - seasonal[i]
+		}
+	}
+	return r.decomposition(trend, seasonal, residual), nil
+}
+
+// classical returns the trend and seasonal components of the classical decomposition of 'r'.
+func classical(r *regular, season Season, model Model) (trend, seasonal []float64) {
+	n, p := len(r.values), season.Period
+	trend = make([]float64, n)
+	half := p / 2
+	for i := range trend {
+		trend[i] = math.NaN()
+		if i < half || i+half >= n {
+			continue
+		}
+		var sum float64
+		for j := i - half; j <= i+half; j++ {
+			w := 1.0
+			if p%2 == 0 && (j == i-half || j == i+half) {
+				w = 0.5 // a 2x'p' moving average for even periods.
+			}
+			sum += w * r.values[j]
+		}
+		trend[i] = sum / float64(p)
+	}
+
+	// average detrended value by position, on original points only.
+	sums, counts := make([]float64, p), make([]float64, p)
+	for i, v := range r.values {
+		if math.IsNaN(trend[i]) || !r.original[i] {
+			continue
+		}
+		k := season.Position(r.times[i])
+		counts[k]++
+		if model == Multiplicative {
+			sums[k] += v / trend[i]
+		} else {
+			sums[k] += v - trend[i]
+		}
+	}
+	// normalize so that the seasonal component averages to 0 (or 1) over a season. Positions without
+	// any original point are skipped, and get a neutral seasonal component.
+	var norm, defined float64
+	for k := range sums {
+		if counts[k] == 0 {
+			continue
+		}
+		sums[k] /= counts[k]
+		norm += sums[k]
+		defined++
+	}
+	norm /= defined
+	seasonal = make([]float64, n)
+	for i := range seasonal {
+		k := season.Position(r.times[i])
+		switch {
+		case counts[k] == 0 && model == Multiplicative:
+			seasonal[i] = 1
+		case counts[k] == 0:
+			seasonal[i] = 0
+		case model == Multiplicative:
+			seasonal[i] = sums[k] / norm
+		default:
+			seasonal[i] = sums[k] - norm
+		}
+	}
+	return trend, seasonal
+}
@@ -0,0 +1,139 @@
+package decompose_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/decompose"
+)
+
+// weekly is the weekly pattern used in tests, indexed by weekday.
+var weekly = []float64{-3, 1, 2, 1, 0, -1, 0}
+
+// daily returns a daily support from 2000-1-1 for 'n' days with a linear trend and a weekly pattern,
+// skipping every 10th day.
+func daily(n int) *timeserie.Support {
+	s := new(timeserie.Support)
+	for i := 0; i < n; i++ {
+		if i%10 == 5 {
+			continue
+		}
+		t := timeserie.DayDate(2000, 1, 1+i)
+		s.Append(t, 100+0.1*float64(i)+weekly[t.Weekday()])
+	}
+	return s
+}
+
+// check verifies that the seasonal component matches the weekly pattern, and that residuals are
+// small, on points away from the bounds.
+func check(t *testing.T, name string, d *decompose.Decomposition, tolerance float64) {
+	t.Helper()
+	seasonal := timeserie.New(d.Seasonal, timeserie.ModeNullset)
+	residual := timeserie.New(d.Residual, timeserie.ModeNullset)
+	for on := range d.Trend.Times() {
+		if on.Before(timeserie.DayDate(2000, 2, 1)) || on.After(timeserie.DayDate(2000, 4, 1)) {
+			continue
+		}
+		if got, want := seasonal.F(on), weekly[on.Weekday()]; math.Abs(got-want) > tolerance {
+			t.Errorf("%s seasonal(%v) = %v want %v", name, on.Format(time.DateOnly), got, want)
+		}
+		if got := residual.F(on); math.Abs(got) > tolerance {
+			t.Errorf("%s residual(%v) = %v want 0", name, on.Format(time.DateOnly), got)
+		}
+	}
+}
+
+// TestClassical checks the additive decomposition with missing days.
+func TestClassical(t *testing.T) {
+	s := daily(120)
+	d, err := decompose.Classical(s, decompose.Weekly, decompose.Additive)
+	if err != nil {
+		t.Fatalf("Classical() error: %v", err)
+	}
+	check(t, "Classical", d, 1e-3)
+	if d.Seasonal.Len() != s.Len() {
+		t.Errorf("Classical() seasonal Len = %v want %v", d.Seasonal.Len(), s.Len())
+	}
+}
+
+// TestClassical_emptyPosition checks that a position without any point does not spoil the others.
+func TestClassical_emptyPosition(t *testing.T) {
+	s := new(timeserie.Support)
+	for on, v := range daily(120).Values() {
+		if on.Weekday() != time.Sunday {
+			s.Append(on, v)
+		}
+	}
+	d, err := decompose.Classical(s, decompose.Weekly, decompose.Additive)
+	if err != nil {
+		t.Fatalf("Classical() error: %v", err)
+	}
+	for on, v := range d.Seasonal.Values() {
+		if math.IsNaN(v) {
+			t.Fatalf("Classical() seasonal(%v) = NaN", on.Format(time.DateOnly))
+		}
+	}
+}
+
+// TestClassical_multiplicative checks a pure multiplicative series.
+func TestClassical_multiplicative(t *testing.T) {
+	s := new(timeserie.Support)
+	for i := 0; i < 70; i++ {
+		t := timeserie.DayDate(2000, 1, 1+i)
+		s.Append(t, 100*(1+weekly[t.Weekday()]/10))
+	}
+	d, err := decompose.Classical(s, decompose.Weekly, decompose.Multiplicative)
+	if err != nil {
+		t.Fatalf("Classical() error: %v", err)
+	}
+	for on, v := range d.Seasonal.Values() {
+		if want := 1 + weekly[on.Weekday()]/10; math.Abs(v-want) > 1e-9 {
+			t.Errorf("Classical(multiplicative) seasonal(%v) = %v want %v", on.Format(time.DateOnly), v, want)
+		}
+	}
+}
+
+// TestSTL checks the decomposition with missing days and an outlier.
+func TestSTL(t *testing.T) {
+	s := daily(120)
+	d, err := decompose.STL(s, decompose.Weekly, 7, false)
+	if err != nil {
+		t.Fatalf("STL() error: %v", err)
+	}
+	check(t, "STL", d, 0.05)
+
+	outlier := timeserie.DayDate(2000, 3, 1)
+	s.Upsert(outlier, 200)
+	d, err = decompose.STL(s, decompose.Weekly, 7, true)
+	if err != nil {
+		t.Fatalf("STL(robust) error: %v", err)
+	}
+	if r := timeserie.New(d.Residual, timeserie.ModeNullset).F(outlier); r < 50 {
+		t.Errorf("STL(robust) residual(%v) = %v want the outlier", outlier.Format(time.DateOnly), r)
+	}
+}
+
+// TestYearly checks the leap year handling of the yearly season.
+func TestYearly(t *testing.T) {
+	if p := decompose.Yearly.Position(timeserie.DayDate(2000, 2, 29)); p != 58 {
+		t.Errorf("Yearly.Position(2000-2-29) = %v want 58", p)
+	}
+	if p := decompose.Yearly.Position(timeserie.DayDate(2000, 12, 31)); p != 364 {
+		t.Errorf("Yearly.Position(2000-12-31) = %v want 364", p)
+	}
+	s := new(timeserie.Support)
+	for _, on := range timeserie.Every(timeserie.DayDate(1999, 1, 1), timeserie.DayDate(2003, 1, 1), timeserie.Day) {
+		s.Append(on, 10+math.Sin(2*math.Pi*float64(decompose.Yearly.Position(on))/365))
+	}
+	d, err := decompose.STL(s, decompose.Yearly, 7, false)
+	if err != nil {
+		t.Fatalf("STL(yearly) error: %v", err)
+	}
+	for on, v := range d.Residual.Values() {
+		if math.Abs(v) > 0.1 {
+			t.Errorf("STL(yearly) residual(%v) = %v want 0", on.Format(time.DateOnly), v)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package decompose
+
+import (
+	"math"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// STL returns the Seasonal-Trend decomposition using Loess of 's', with an additive model.
+//
+// 'window' is the number of seasons used to smooth each position of the season (odd, at least 7
+// is recommended). If 'robust', points with large residuals are given a lower weight, so that
+// outliers do not leak into the trend and seasonal components.
+//
+// Missing points are linearly interpolated, then refilled with the fitted values for the computation.
+func STL(s *timeserie.Support, season Season, window int, robust bool) (*Decomposition, error) {
+	r, err := regularize(s, season)
+	if err != nil {
+		return nil, err
+	}
+	n, p := len(r.values), season.Period
+	window = odd(max(window, 3))
+	lowpass := odd(p)
+	trendWindow := odd(int(math.Ceil(1.5 * float64(p) / (1 - 1.5/float64(window)))))
+	inner, outer := 2, 0
+	if robust {
+		inner, outer = 1, 15
+	}
+
+	// cycle-subseries: indexes of the points at each position.
+	subseries := make([][]int, p)
+	for i, t := range r.times {
+		k := season.Position(t)
+		subseries[k] = append(subseries[k], i)
+	}
+
+	y := r.values
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+	trend := make([]float64, n)
+	seasonal := make([]float64, n)
+	detrended := make([]float64, n)
+	cycle := make([]float64, n)
+	for o := 0; o <= outer+refills; o++ {
+		for range inner {
+			// 1. smooth each cycle-subseries of the detrended values.
+			for i := range y {
+				detrended[i] = y[i] - trend[i]
+			}
+			for _, idx := range subseries {
+				times, ys, ws := make([]time.Time, len(idx)), make([]float64, len(idx)), make([]float64, len(idx))
+				for j, i := range idx {
+					times[j], ys[j], ws[j] = r.times[i], detrended[i], weights[i]
+				}
+				smoothed, err := loess(times, ys, ws, window)
+				if err != nil {
+					return nil, err
+				}
+				for j, v := range smoothed {
+					cycle[idx[j]] = v
+				}
+			}
+			// 2. remove the low frequencies from the cycle-subseries.
+			low := movingAverage(movingAverage(movingAverage(cycle, p), p), 3)
+			if low, err = loess(r.times, low, nil, lowpass); err != nil {
+				return nil, err
+			}
+			for i := range seasonal {
+				seasonal[i] = cycle[i] - low[i]
+			}
+			// 3. smooth the deseasonalized values.
+			deseasonalized := make([]float64, n)
+			for i := range y {
+				deseasonalized[i] = y[i] - seasonal[i]
+			}
+			if trend, err = loess(r.times, deseasonalized, weights, trendWindow); err != nil {
+				return nil, err
+			}
+		}
+		if o >= outer {
+			// refill missing points with the fitted values, and run again.
+			r.refill(trend, seasonal, Additive)
+			continue
+		}
+		// robustness weights from the residuals.
+		residuals := make([]float64, n)
+		for i := range y {
+			residuals[i] = math.Abs(y[i] - trend[i] - seasonal[i])
+		}
+		h := 6 * timeserie.Median(residuals)
+		if h == 0 {
+			break // perfect fit.
+		}
+		for i, e := range residuals {
+			weights[i] = bisquare(e / h)
+		}
+	}
+	residual := make([]float64, n)
+	for i := range y {
+		residual[i] = y[i] - trend[i] - seasonal[i]
+	}
+	return r.decomposition(trend, seasonal, residual), nil
+}
+
+// loess returns the WeightedLOESS of 'ys' at 'times', using the 'q' nearest neighbours and the
+// robustness weights 'ws'.
+func loess(times []time.Time, ys, ws []float64, q int) ([]float64, error) {
+	s := new(timeserie.Support)
+	for i, t := range times {
+		s.Append(t, ys[i])
+	}
+	smoothed, err := s.WeightedLOESS(q, ws)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]float64, 0, len(ys))
+	for _, v := range smoothed.Values() {
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// movingAverage returns the centered moving average of 'x' over 'w' points, truncated at the bounds.
+func movingAverage(x []float64, w int) []float64 {
+	res := make([]float64, len(x))
+	for i := range x {
+		a, b := max(0, i-w/2), min(len(x), i-w/2+w)
+		var sum float64
+		for _, v := range x[a:b] {
+			sum += v
+		}
+		res[i] = sum / float64(b-a)
+	}
+	return res
+}
+
+// odd returns 'n' if odd, 'n'+1 otherwise.
+func odd(n int) int { return n | 1 }
+
+// bisquare returns the robustness weight of a residual 'u', scaled so that residuals above 1 have
+// a zero weight.
+func bisquare(u float64) float64 {
+	if u >= 1 {
+		return 0
+	}
+	c := 1 - u*u
+	return c * c
+}
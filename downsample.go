@@ -0,0 +1,130 @@
+package timeserie
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Here goes downsampling methods, to reduce the number of points of a support while preserving its
+// visual shape. They all keep the first and last points, and only keep existing points.
+
+// LTTB returns a new Support with at most 'n' points selected by the Largest-Triangle-Three-Buckets
+// algorithm.
+func (s *Support) LTTB(n int) *Support {
+	if n >= s.Len() || n < 3 {
+		return s.bounds(n)
+	}
+	// x coordinates are seconds since the first point, to keep precision.
+	x := func(i int) float64 { return s.times[i].Sub(s.times[0]).Seconds() }
+	res := new(Support)
+	res.Append(s.times[0], s.values[0])
+	size := float64(s.Len()-2) / float64(n-2) // bucket size, first and last excluded.
+	a := 0                                    // selected point in the previous bucket.
+	for b := 0; b < n-2; b++ {
+		start, end := int(float64(b)*size)+1, int(float64(b+1)*size)+1
+		// average point of the next bucket.
+		nextStart, nextEnd := end, min(int(float64(b+2)*size)+1, s.Len())
+		var ax, ay float64
+		for i := nextStart; i < nextEnd; i++ {
+			ax += x(i)
+			ay += s.values[i]
+		}
+		ax, ay = ax/float64(nextEnd-nextStart), ay/float64(nextEnd-nextStart)
+
+		best, area := start, -1.0
+		xa, ya := x(a), s.values[a]
+		for i := start; i < end; i++ {
+			if ar := math.Abs((xa-ax)*(s.values[i]-ya) - (xa-x(i))*(ay-ya)); ar > area {
+				best, area = i, ar
+			}
+		}
+		res.Append(s.times[best], s.values[best])
+		a = best
+	}
+	last := s.Len() - 1
+	res.Append(s.times[last], s.values[last])
+	return res
+}
+
+// MinMax returns a new Support with at most 'n' points: the first and last points, and the minimum
+// and maximum of (n-2)/2 buckets with the same number of points.
+func (s *Support) MinMax(n int) *Support {
+	if n >= s.Len() || n < 4 {
+		return s.bounds(n)
+	}
+	buckets := (n - 2) / 2
+	size := float64(s.Len()-2) / float64(buckets)
+	keep := []int{0}
+	for b := range buckets {
+		start, end := int(float64(b)*size)+1, int(float64(b+1)*size)+1
+		lo, hi := s.extrema(start, end)
+		keep = append(keep, lo, hi)
+	}
+	keep = append(keep, s.Len()-1)
+	return s.keep(keep)
+}
+
+// M4 returns a new Support with at most 'n' points: the first, last, minimum and maximum points of
+// n/4 buckets of the same duration.
+func (s *Support) M4(n int) *Support {
+	if n >= s.Len() || n < 4 {
+		return s.bounds(n)
+	}
+	buckets := n / 4
+	first, last := s.times[0], s.times[s.Len()-1]
+	span := float64(last.Sub(first))
+	var keep []int
+	start := 0
+	for b := range buckets {
+		end := s.Len()
+		if b < buckets-1 {
+			limit := first.Add(time.Duration(span * float64(b+1) / float64(buckets)))
+			end = s.lower(limit)
+		}
+		if end > start {
+			lo, hi := s.extrema(start, end)
+			keep = append(keep, start, lo, hi, end-1)
+		}
+		start = end
+	}
+	return s.keep(keep)
+}
+
+// extrema returns the index of the minimum and maximum values in [start, end).
+func (s *Support) extrema(start, end int) (lo, hi int) {
+	lo, hi = start, start
+	for i := start; i < end; i++ {
+		if s.values[i] < s.values[lo] {
+			lo = i
+		}
+		if s.values[i] > s.values[hi] {
+			hi = i
+		}
+	}
+	return lo, hi
+}
+
+// keep returns a new support with points at indexes 'keep', without duplicates.
+func (s *Support) keep(keep []int) *Support {
+	slices.Sort(keep)
+	keep = slices.Compact(keep)
+	res := &Support{times: make([]time.Time, 0, len(keep)), values: make([]float64, 0, len(keep)), meta: s.meta.Clone()}
+	for _, i := range keep {
+		res.times, res.values = append(res.times, s.times[i]), append(res.values, s.values[i])
+	}
+	return res
+}
+
+// bounds returns a copy of 's' if it has at most 'n' points, or only its first and last points.
+func (s *Support) bounds(n int) *Support {
+	switch {
+	case n >= s.Len():
+		return s.Clone()
+	case n <= 0:
+		return &Support{meta: s.meta.Clone()}
+	case n == 1:
+		return s.keep([]int{0})
+	}
+	return s.keep([]int{0, s.Len() - 1})
+}
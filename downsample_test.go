@@ -0,0 +1,90 @@
+package timeserie_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// wave returns a support of 'n' points every minute with a noisy sine wave.
+func wave(n int) *timeserie.Support {
+	s := new(timeserie.Support)
+	for i := 0; i < n; i++ {
+		s.Append(d0.Add(time.Duration(i)*time.Minute), math.Sin(float64(i)/100)+0.1*math.Sin(float64(i*i)))
+	}
+	return s
+}
+
+// TestDownsample checks the number of points, the bounds, and that points are taken from the support.
+func TestDownsample(t *testing.T) {
+	s := wave(10000)
+	f := timeserie.New(s, timeserie.ModeNullset)
+	first, _ := s.At(0)
+	last, _ := s.At(s.Len() - 1)
+	for _, test := range []struct {
+		name string
+		x    *timeserie.Support
+	}{
+		{"LTTB", s.LTTB(100)},
+		{"MinMax", s.MinMax(100)},
+		{"M4", s.M4(100)},
+	} {
+		x := test.x
+		if x.Len() > 100 || x.Len() < 50 {
+			t.Errorf("%s(100).Len() = %v want about 100", test.name, x.Len())
+		}
+		if err := x.Validate(); err != nil {
+			t.Errorf("%s(100).Validate() = %v", test.name, err)
+		}
+		if on, _ := x.At(0); on != first {
+			t.Errorf("%s(100) first = %v want %v", test.name, on, first)
+		}
+		if on, _ := x.At(x.Len() - 1); on != last {
+			t.Errorf("%s(100) last = %v want %v", test.name, on, last)
+		}
+		for on, v := range x.Values() {
+			if f.F(on) != v {
+				t.Errorf("%s(100) has %v, %v not in the support", test.name, on, v)
+			}
+		}
+	}
+	if x := s.LTTB(20000); x.Len() != s.Len() {
+		t.Errorf("LTTB(20000).Len() = %v want %v", x.Len(), s.Len())
+	}
+}
+
+// TestSupport_MinMax checks that extrema are preserved.
+func TestSupport_MinMax(t *testing.T) {
+	s := wave(10000)
+	s.Upsert(d0.Add(5000*time.Minute), 100)
+	x := s.MinMax(10)
+	if f := timeserie.New(x, timeserie.ModeNullset); f.F(d0.Add(5000*time.Minute)) != 100 {
+		t.Errorf("MinMax(10) lost the maximum")
+	}
+}
+
+func BenchmarkSupport_LTTB(b *testing.B) {
+	s := wave(1000000)
+	b.ResetTimer()
+	for range b.N {
+		s.LTTB(1000)
+	}
+}
+
+func BenchmarkSupport_MinMax(b *testing.B) {
+	s := wave(1000000)
+	b.ResetTimer()
+	for range b.N {
+		s.MinMax(1000)
+	}
+}
+
+func BenchmarkSupport_M4(b *testing.B) {
+	s := wave(1000000)
+	b.ResetTimer()
+	for range b.N {
+		s.M4(1000)
+	}
+}
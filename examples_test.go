@@ -1,13 +1,15 @@
 package timeserie_test
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/etnz/timeserie"
 )
 
-func Example_Load() {
+func ExampleLoad() {
 
 	source := `
 	{ "on":"01-1-2", "ts1":2, "ts2":4}
@@ -23,6 +25,53 @@ func Example_Load() {
 	}
 
 	//Output:
-	// { "on":"01-1-1", "ts2":1, "ts1":1}
-	// { "on":"01-1-2", "ts2":4, "ts1":2}
+	// { "on":"01-1-1", "ts1":1, "ts2":1}
+	// { "on":"01-1-2", "ts1":2, "ts2":4}
+}
+
+func ExampleFormat_intraday() {
+	dict := map[string]*timeserie.Support{"load": new(timeserie.Support)}
+	dict["load"].Append(timeserie.DayDate(2001, 1, 1), 1)
+	dict["load"].Append(timeserie.DayDate(2001, 1, 1).Add(90*time.Minute), 2)
+	var b strings.Builder
+	if err := timeserie.Format(&b, dict); err != nil {
+		panic(err)
+	}
+	// and back, without loss of precision
+	loaded := make(map[string]*timeserie.Support)
+	if err := timeserie.Load(loaded, strings.NewReader(b.String())); err != nil {
+		panic(err)
+	}
+	if err := timeserie.Format(os.Stdout, loaded); err != nil {
+		panic(err)
+	}
+
+	//Output:
+	// { "on":"01-1-1", "load":1}
+	// { "on":"2001-01-01T01:30:00Z", "load":2}
+}
+
+func ExampleFrameOf() {
+	source := `
+	{ "on":"01-1-1", "ts1":1, "ts2":1}
+	{ "on":"01-1-2", "ts1":2}
+	`
+	dict := make(map[string]*timeserie.Support)
+	if err := timeserie.Load(dict, strings.NewReader(source)); err != nil {
+		panic(err)
+	}
+	fr := timeserie.FrameOf(dict)
+	for on, row := range fr.Rows() {
+		fmt.Println(on.Format("2006-01-02"), row)
+	}
+	// and back to the dump format
+	if err := timeserie.Format(os.Stdout, fr.Dict()); err != nil {
+		panic(err)
+	}
+
+	//Output:
+	// 2001-01-01 [1 1]
+	// 2001-01-02 [2 NaN]
+	// { "on":"01-1-1", "ts1":1, "ts2":1}
+	// { "on":"01-1-2", "ts1":2}
 }
@@ -0,0 +1,221 @@
+package expr
+
+import (
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/units"
+)
+
+// value is either a constant or a function.
+type value struct {
+	f *timeserie.Function // nil for constants.
+	c float64
+}
+
+// modes are the mode changing functions.
+var modes = map[string]timeserie.Mode{
+	"nullset": timeserie.ModeNullset,
+	"step":    timeserie.ModeStep,
+	"linear":  timeserie.ModeLinear,
+}
+
+// Eval evaluates the expression on the series in 'dict'.
+//
+// A constant expression is an error, as it has no support.
+func (e *Expr) Eval(dict map[string]*timeserie.Support) (*timeserie.Function, error) {
+	v, err := eval(e.root, dict)
+	if err != nil {
+		return nil, err
+	}
+	if v.f == nil {
+		return nil, start(e.root).errorf("expression is a constant, it must depend on a series")
+	}
+	return v.f, nil
+}
+
+// Eval evaluates all assignments in order, each result is stored in 'dict' so that it can be used by
+// the next assignments.
+func (p *Program) Eval(dict map[string]*timeserie.Support) error {
+	for _, a := range p.assignments {
+		f, err := (&Expr{a.x}).Eval(dict)
+		if err != nil {
+			return err
+		}
+		dict[a.name] = &f.Support
+	}
+	return nil
+}
+
+// start returns the position of the first token of 'n'.
+func start(n node) pos {
+	if b, ok := n.(*binary); ok {
+		return start(b.x)
+	}
+	return n.position()
+}
+
+// eval evaluates the node 'n'.
+func eval(n node, dict map[string]*timeserie.Support) (value, error) {
+	switch n := n.(type) {
+	case *number:
+		return value{c: n.value}, nil
+	case *ident:
+		s, ok := dict[n.name]
+		if !ok {
+			return value{}, n.errorf("unknown series %q", n.name)
+		}
+		return value{f: timeserie.New(s, timeserie.ModeNullset)}, nil
+	case *unary:
+		x, err := eval(n.x, dict)
+		if err != nil {
+			return value{}, err
+		}
+		return apply(x, func(v float64) float64 { return -v }), nil
+	case *binary:
+		x, err := eval(n.x, dict)
+		if err != nil {
+			return value{}, err
+		}
+		y, err := eval(n.y, dict)
+		if err != nil {
+			return value{}, err
+		}
+		v, err := arithmetic(n.op, x, y)
+		if err != nil {
+			return value{}, n.errorf("%v", err)
+		}
+		return v, nil
+	case *call:
+		return evalCall(n, dict)
+	}
+	panic("unknown node") // all nodes are handled above.
+}
+
+// apply returns op applied to each value of 'x', in the same unit.
+func apply(x value, op func(float64) float64) value {
+	if x.f == nil {
+		return value{c: op(x.c)}
+	}
+	s := new(timeserie.Support)
+	for t, v := range x.f.Values() {
+		s.Append(t, op(v))
+	}
+	return value{f: units.With(timeserie.New(s, x.f.Mode()), x.f.Meta().Unit)}
+}
+
+// ops are the arithmetic operators on constants.
+var ops = map[string]func(a, b float64) float64{
+	"+": func(a, b float64) float64 { return a + b },
+	"-": func(a, b float64) float64 { return a - b },
+	"*": func(a, b float64) float64 { return a * b },
+	"/": func(a, b float64) float64 { return a / b },
+}
+
+// unchecked are the arithmetic operators on series without unit.
+var unchecked = map[string]func(a, b *timeserie.Function) *timeserie.Function{
+	"+": func(a, b *timeserie.Function) *timeserie.Function { return timeserie.Add(a, b) },
+	"-": timeserie.Sub,
+	"*": func(a, b *timeserie.Function) *timeserie.Function { return timeserie.Times(a, b) },
+	"/": timeserie.Div,
+}
+
+// arithmetic returns x op y. Units of series are checked and propagated, unless neither has a unit,
+// and constants have the unit of the series they are combined with.
+func arithmetic(op string, x, y value) (value, error) {
+	switch {
+	case x.f != nil && y.f != nil && x.f.Meta().Unit == "" && y.f.Meta().Unit == "":
+		return value{f: unchecked[op](x.f, y.f)}, nil
+	case x.f != nil && y.f != nil:
+		var f *timeserie.Function
+		var err error
+		switch op {
+		case "+":
+			f, err = units.Add(x.f, y.f)
+		case "-":
+			f, err = units.Sub(x.f, y.f)
+		case "*":
+			f, err = units.Times(x.f, y.f)
+		default:
+			f, err = units.Div(x.f, y.f)
+		}
+		return value{f: f}, err
+	case x.f != nil:
+		return apply(x, func(v float64) float64 { return ops[op](v, y.c) }), nil
+	case y.f != nil:
+		v := apply(y, func(v float64) float64 { return ops[op](x.c, v) })
+		if op == "/" {
+			v.f = units.With(v.f, inverse(v.f.Meta().Unit))
+		}
+		return v, nil
+	}
+	return value{c: ops[op](x.c, y.c)}, nil
+}
+
+// inverse returns the unit 1/unit, or "" if it is unknown.
+func inverse(unit string) string {
+	u, err := units.Parse(unit)
+	if err != nil {
+		return ""
+	}
+	one, _ := units.Parse("1")
+	return one.Div(u).String()
+}
+
+// periods maps the periods of sample onto the keys of timeserie.Periods, which are also accepted.
+var periods = map[string]string{
+	"daily":     "day",
+	"weekly":    "week",
+	"monthly":   "month",
+	"quarterly": "quarter",
+	"yearly":    "year",
+}
+
+// evalCall evaluates a function call.
+func evalCall(n *call, dict map[string]*timeserie.Support) (value, error) {
+	arity := 1
+	if n.name == "sample" {
+		arity = 2
+	}
+	switch n.name {
+	case "delta", "acc", "step", "linear", "nullset", "sample":
+	default:
+		return value{}, n.errorf("unknown function %q", n.name)
+	}
+	if len(n.args) != arity {
+		return value{}, n.errorf("%s expects %d argument(s) got %d", n.name, arity, len(n.args))
+	}
+	x, err := eval(n.args[0], dict)
+	if err != nil {
+		return value{}, err
+	}
+	if x.f == nil {
+		return value{}, n.args[0].position().errorf("%s expects a series got a constant", n.name)
+	}
+	unit := x.f.Meta().Unit
+	switch n.name {
+	case "delta":
+		return value{f: units.With(timeserie.New(x.f.Delta(), x.f.Mode()), unit)}, nil
+	case "acc":
+		return value{f: units.With(timeserie.New(x.f.Scan(0, timeserie.ScannerAcc), x.f.Mode()), unit)}, nil
+	case "sample":
+		id, ok := n.args[1].(*ident)
+		if !ok {
+			return value{}, n.args[1].position().errorf("sample expects a period: daily, weekly, monthly, quarterly or yearly")
+		}
+		name := id.name
+		if p, ok := periods[name]; ok {
+			name = p
+		}
+		cond, ok := timeserie.Periods[name]
+		if !ok {
+			return value{}, id.errorf("unknown period %q: expected daily, weekly, monthly, quarterly or yearly", id.name)
+		}
+		if x.f.Len() == 0 {
+			return x, nil
+		}
+		first, _ := x.f.At(0)
+		last, _ := x.f.At(x.f.Len() - 1)
+		return value{f: units.With(timeserie.Sample(timeserie.Days(first, last.Add(timeserie.Day), cond), x.f), unit)}, nil
+	}
+	// mode changing functions.
+	return value{f: timeserie.New(&x.f.Support, modes[n.name])}, nil
+}
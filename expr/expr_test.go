@@ -0,0 +1,162 @@
+package expr_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/expr"
+)
+
+// dump returns the dictionary of a value change dump.
+func dump(t *testing.T, src string) map[string]*timeserie.Support {
+	t.Helper()
+	dict := make(map[string]*timeserie.Support)
+	if err := timeserie.Load(dict, strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	return dict
+}
+
+// format returns the value change dump of 'dict'.
+func format(t *testing.T, dict map[string]*timeserie.Support) string {
+	t.Helper()
+	var b strings.Builder
+	if err := timeserie.Format(&b, dict); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+// TestProgram_Eval checks arithmetic, precedence, constants and functions.
+func TestProgram_Eval(t *testing.T) {
+	src := `
+	{ "on":"24-1-1", "income":100, "expenses":60}
+	{ "on":"24-1-15", "expenses":20}
+	{ "on":"24-2-1", "income":100, "expenses":70}
+	`
+	for _, test := range []struct {
+		program string
+		want    string
+	}{
+		{"x = income - expenses", `{ "on":"24-1-1", "x":40}` + "\n" + `{ "on":"24-2-1", "x":30}` + "\n"},
+		{"x = 2 * income - -expenses / 2", `{ "on":"24-1-1", "x":230}` + "\n" + `{ "on":"24-2-1", "x":235}` + "\n"},
+		{"x = (income - expenses) * 2", `{ "on":"24-1-1", "x":80}` + "\n" + `{ "on":"24-2-1", "x":60}` + "\n"},
+		{"x = acc(expenses)", `{ "on":"24-1-1", "x":60}` + "\n" + `{ "on":"24-1-15", "x":80}` + "\n" + `{ "on":"24-2-1", "x":150}` + "\n"},
+		{"x = delta(income)", `{ "on":"24-2-1", "x":0}` + "\n"},
+		{"x = step(income) - expenses", `{ "on":"24-1-1", "x":40}` + "\n" + `{ "on":"24-1-15", "x":80}` + "\n" + `{ "on":"24-2-1", "x":30}` + "\n"},
+		{"x = sample(step(expenses), monthly)", `{ "on":"24-1-1", "x":60}` + "\n" + `{ "on":"24-2-1", "x":70}` + "\n"},
+		{"x = sample(step(expenses), month)", `{ "on":"24-1-1", "x":60}` + "\n" + `{ "on":"24-2-1", "x":70}` + "\n"},
+		{"y = income / 100; x = y + 1", `{ "on":"24-1-1", "x":2}` + "\n" + `{ "on":"24-2-1", "x":2}` + "\n"},
+	} {
+		dict := dump(t, src)
+		p, err := expr.Parse(test.program)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", test.program, err)
+			continue
+		}
+		if err := p.Eval(dict); err != nil {
+			t.Errorf("Eval(%q) error: %v", test.program, err)
+			continue
+		}
+		got := format(t, map[string]*timeserie.Support{"x": dict["x"]})
+		if got != test.want {
+			t.Errorf("Eval(%q) =\n%s\nwant\n%s", test.program, got, test.want)
+		}
+	}
+}
+
+// TestErrors checks error positions.
+func TestErrors(t *testing.T) {
+	dict := dump(t, `{ "on":"24-1-1", "a":1}`)
+	for _, test := range []struct {
+		program string
+		want    string
+	}{
+		{"x = a +", "1:8: unexpected end of input"},
+		{"x = a $ 1", "1:7: unexpected character '$'"},
+		{"x = a\ny = b", "2:5: unknown series \"b\""},
+		{"x = 1 + 2", "1:5: expression is a constant, it must depend on a series"},
+		{"x = foo(a)", "1:5: unknown function \"foo\""},
+		{"x = sample(a, hourly)", "1:15: unknown period \"hourly\": expected daily, weekly, monthly, quarterly or yearly"},
+		{"x = (a", "1:7: expected \")\" got end of input"},
+		{"x = a a", "1:7: unexpected \"a\" after expression"},
+	} {
+		p, err := expr.Parse(test.program)
+		if err == nil {
+			err = p.Eval(dict)
+		}
+		var e *expr.Error
+		if !errors.As(err, &e) || err.Error() != test.want {
+			t.Errorf("%q error = %v want %v", test.program, err, test.want)
+		}
+	}
+}
+
+// TestParseExpr checks a single expression.
+func TestParseExpr(t *testing.T) {
+	dict := dump(t, `{ "on":"24-1-1", "a":1, "b":2}`)
+	e, err := expr.ParseExpr("a + b * 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := e.Eval(dict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, v := f.At(0); v != 7 {
+		t.Errorf("a + b * 3 = %v want 7", v)
+	}
+	if _, err := expr.ParseExpr("a = b"); err == nil {
+		t.Errorf("ParseExpr(a = b) error = nil want error")
+	}
+}
+
+// TestUnits checks that units are propagated and checked.
+func TestUnits(t *testing.T) {
+	src := `{"meta":{"id":"power","unit":"kW"}}
+{"meta":{"id":"hours","unit":"h"}}
+{"meta":{"id":"price","unit":"EUR/MWh"}}
+{ "on":"24-1-1", "power":2, "hours":24, "price":100}
+`
+	for _, test := range []struct {
+		program string
+		unit    string
+		value   float64
+	}{
+		{"x = power * hours", "kWh", 48},
+		{"x = power * hours * price", "mEUR", 4800},
+		{"x = 1 / hours", "1/h", 1.0 / 24},
+		{"x = acc(power) - power", "kW", 0},
+	} {
+		dict := dump(t, src)
+		p, err := expr.Parse(test.program)
+		if err == nil {
+			err = p.Eval(dict)
+		}
+		if err != nil {
+			t.Errorf("Eval(%q) error: %v", test.program, err)
+			continue
+		}
+		x := dict["x"]
+		if _, v := x.At(x.Len() - 1); x.Meta().Unit != test.unit || math.Abs(v-test.value) > 1e-9 {
+			t.Errorf("Eval(%q) = %v %s, want %v %s", test.program, v, x.Meta().Unit, test.value, test.unit)
+		}
+	}
+
+	p, _ := expr.Parse("x = power + price")
+	want := `1:11: cannot add "kW" and "EUR/MWh": incompatible dimensions`
+	if err := p.Eval(dump(t, src)); err == nil || err.Error() != want {
+		t.Errorf("Eval(power + price) error = %v, want %s", err, want)
+	}
+
+	p, _ = expr.Parse("x = power * count")
+	dict := dump(t, src)
+	dict["count"] = dict["power"].Clone()
+	dict["count"].SetMeta(timeserie.Meta{})
+	if err := p.Eval(dict); err == nil || !strings.Contains(err.Error(), "series without unit") {
+		t.Errorf("Eval(power * count) error = %v, want a series without unit", err)
+	}
+}
@@ -0,0 +1,326 @@
+// Package expr parses and evaluates arithmetic expressions over named timeserie Supports.
+//
+// A program is a list of assignments separated by semicolons or new lines:
+//
+//	net = income - expenses
+//	ratio = savings / income
+//
+// Expressions combine series names, numeric constants, the operators + - * / and parentheses,
+// and function calls:
+//
+//	delta(x)          the differences between consecutive points, see Support.Delta
+//	acc(x)            the cumulative sum, see Support.Scan
+//	step(x)           x with ModeStep
+//	linear(x)         x with ModeLinear
+//	nullset(x)        x with ModeNullset
+//	sample(x, period) x sampled at the start of each period: daily, weekly, monthly, quarterly or yearly
+//
+// Series are read with ModeNullset, and operators between series map onto units.Add, Sub, Times and
+// Div, that check and propagate units. Series without unit are only combined with series without
+// unit, with timeserie.Add, Sub, Times and Div.
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Error is a parsing or evaluation error at a position in the source.
+type Error struct {
+	Line, Col int // 1-based position.
+	Msg       string
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("%d:%d: %s", e.Line, e.Col, e.Msg) }
+
+// pos is a position in the source.
+type pos struct{ line, col int }
+
+// errorf returns an Error at 'p'.
+func (p pos) errorf(format string, args ...any) error {
+	return &Error{Line: p.line, Col: p.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// kind is the kind of a token.
+type kind int
+
+const (
+	tEOF kind = iota
+	tSep      // ';' or new line
+	tIdent
+	tNumber
+	tOp // one of + - * / ( ) , =
+)
+
+type token struct {
+	kind kind
+	text string
+	pos  pos
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tEOF:
+		return "end of input"
+	case tSep:
+		return "end of statement"
+	}
+	return strconv.Quote(t.text)
+}
+
+// lex splits 'src' into tokens.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	p := pos{1, 1}
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := p
+		switch {
+		case r == '\n' || r == ';':
+			tokens = append(tokens, token{tSep, string(r), start})
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '#': // comment until the end of line.
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+				p.col++
+			}
+			continue
+		case strings.ContainsRune("+-*/(),=", r):
+			tokens = append(tokens, token{tOp, string(r), start})
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || runes[j] == 'e' || runes[j] == 'E' ||
+				((runes[j] == '+' || runes[j] == '-') && (runes[j-1] == 'e' || runes[j-1] == 'E'))) {
+				j++
+			}
+			text := string(runes[i:j])
+			if _, err := strconv.ParseFloat(text, 64); err != nil {
+				return nil, start.errorf("invalid number %q", text)
+			}
+			tokens = append(tokens, token{tNumber, text, start})
+			p.col += j - i
+			i = j
+			continue
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tIdent, string(runes[i:j]), start})
+			p.col += j - i
+			i = j
+			continue
+		default:
+			return nil, start.errorf("unexpected character %q", r)
+		}
+		if r == '\n' {
+			p = pos{p.line + 1, 1}
+		} else {
+			p.col++
+		}
+	}
+	return append(tokens, token{tEOF, "", p}), nil
+}
+
+// node is a node of the expression tree.
+type node interface{ position() pos }
+
+type (
+	number struct {
+		pos
+		value float64
+	}
+	ident struct {
+		pos
+		name string
+	}
+	unary struct {
+		pos
+		op string
+		x  node
+	}
+	binary struct {
+		pos
+		op   string
+		x, y node
+	}
+	call struct {
+		pos
+		name string
+		args []node
+	}
+)
+
+func (p pos) position() pos { return p }
+
+// parser is a recursive descent parser.
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token { return p.tokens[p.i] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tEOF {
+		p.i++
+	}
+	return t
+}
+
+// is returns true if the next token is the operator 'op'.
+func (p *parser) is(op string) bool { t := p.peek(); return t.kind == tOp && t.text == op }
+
+// expect consumes the operator 'op'.
+func (p *parser) expect(op string) error {
+	if t := p.next(); t.kind != tOp || t.text != op {
+		return t.pos.errorf("expected %q got %v", op, t)
+	}
+	return nil
+}
+
+// expr parses: term { ('+' | '-') term }
+func (p *parser) expr() (node, error) {
+	x, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("+") || p.is("-") {
+		t := p.next()
+		y, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{t.pos, t.text, x, y}
+	}
+	return x, nil
+}
+
+// term parses: factor { ('*' | '/') factor }
+func (p *parser) term() (node, error) {
+	x, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("*") || p.is("/") {
+		t := p.next()
+		y, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{t.pos, t.text, x, y}
+	}
+	return x, nil
+}
+
+// factor parses: '-' factor | number | ident | ident '(' args ')' | '(' expr ')'
+func (p *parser) factor() (node, error) {
+	t := p.next()
+	switch {
+	case t.kind == tOp && t.text == "-":
+		x, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{t.pos, "-", x}, nil
+	case t.kind == tOp && t.text == "(":
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	case t.kind == tNumber:
+		v, _ := strconv.ParseFloat(t.text, 64) // checked by the lexer.
+		return &number{t.pos, v}, nil
+	case t.kind == tIdent && p.is("("):
+		p.next()
+		c := &call{pos: t.pos, name: t.text}
+		for !p.is(")") {
+			if len(c.args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			c.args = append(c.args, x)
+		}
+		p.next()
+		return c, nil
+	case t.kind == tIdent:
+		return &ident{t.pos, t.text}, nil
+	}
+	return nil, t.pos.errorf("unexpected %v", t)
+}
+
+// Expr is a parsed expression.
+type Expr struct{ root node }
+
+// ParseExpr parses a single expression.
+func ParseExpr(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	x, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tEOF {
+		return nil, t.pos.errorf("unexpected %v after expression", t)
+	}
+	return &Expr{x}, nil
+}
+
+// assignment is a statement: name = expr.
+type assignment struct {
+	pos
+	name string
+	x    node
+}
+
+// Program is a parsed list of assignments.
+type Program struct{ assignments []assignment }
+
+// Parse parses a program.
+func Parse(src string) (*Program, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	prog := new(Program)
+	for {
+		t := p.next()
+		switch t.kind {
+		case tEOF:
+			return prog, nil
+		case tSep:
+			continue
+		case tIdent:
+		default:
+			return nil, t.pos.errorf("expected a series name got %v", t)
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if end := p.peek(); end.kind != tSep && end.kind != tEOF {
+			return nil, end.pos.errorf("unexpected %v after expression", end)
+		}
+		prog.assignments = append(prog.assignments, assignment{t.pos, t.text, x})
+	}
+}
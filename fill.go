@@ -0,0 +1,127 @@
+package timeserie
+
+import (
+	"math"
+	"time"
+)
+
+// Here goes functions to detect and fill missing points in a support.
+
+// Gap is a sequence of consecutive expected times that have no point in a support.
+type Gap struct {
+	From, To time.Time // First and last missing times, included.
+	Len      int       // Number of missing times.
+}
+
+// Gaps returns the list of gaps of 's' with respect to the 'expected' times, in chronological order.
+//
+// Expected times are usually generated by Days or Every, see also GapsOn.
+func Gaps(s *Support, expected []time.Time) []Gap {
+	var gaps []Gap
+	var current *Gap
+	for _, t := range expected {
+		if s.has(t) {
+			current = nil
+			continue
+		}
+		if current == nil {
+			gaps = append(gaps, Gap{From: t})
+			current = &gaps[len(gaps)-1]
+		}
+		current.To = t
+		current.Len++
+	}
+	return gaps
+}
+
+// GapsOn returns the list of gaps of 's' on the days accepted by 'cond', like Periods["week"], from
+// its first to its last point, in chronological order.
+func GapsOn(s *Support, cond TimeCond) []Gap {
+	if s.Len() == 0 {
+		return nil
+	}
+	return Gaps(s, Days(s.times[0], s.times[s.Len()-1].Add(time.Nanosecond), cond))
+}
+
+// has returns true if the support has a point at time 't'.
+func (s *Support) has(t time.Time) bool {
+	i := s.lower(t)
+	return i < s.Len() && s.times[i].Equal(t)
+}
+
+// Filler computes the value of a missing point at time 't' from support 's'.
+//
+// A Filler returns NaN if it cannot compute a value, the point is then left missing.
+type Filler func(s *Support, t time.Time) float64
+
+var (
+	// FillForward uses the value of the previous point.
+	FillForward Filler = func(s *Support, t time.Time) float64 {
+		if prev := s.Find(t); prev >= 0 {
+			return s.values[prev]
+		}
+		return math.NaN()
+	}
+
+	// FillBackward uses the value of the next point.
+	FillBackward Filler = func(s *Support, t time.Time) float64 {
+		if next := s.upper(t); next < s.Len() {
+			return s.values[next]
+		}
+		return math.NaN()
+	}
+
+	// FillLinear interpolates linearly between the previous and the next points.
+	FillLinear Filler = func(s *Support, t time.Time) float64 {
+		prev := s.Find(t)
+		next := prev + 1
+		if prev < 0 || next >= s.Len() {
+			return math.NaN()
+		}
+		t0, t1 := s.times[prev], s.times[next]
+		v0, v1 := s.values[prev], s.values[next]
+		r := float64(t.Sub(t0)) / float64(t1.Sub(t0))
+		return v0 + r*(v1-v0)
+	}
+)
+
+// FillConstant returns a Filler that always uses 'v'.
+func FillConstant(v float64) Filler {
+	return func(*Support, time.Time) float64 { return v }
+}
+
+// FillSeasonal returns a Filler that uses the value of the point one season before,
+// the season being expressed as in time.AddDate.
+func FillSeasonal(years, months, days int) Filler {
+	return func(s *Support, t time.Time) float64 {
+		prev := t.AddDate(-years, -months, -days)
+		if i := s.lower(prev); i < s.Len() && s.times[i].Equal(prev) {
+			return s.values[i]
+		}
+		return math.NaN()
+	}
+}
+
+// Fill returns a new support where all 'times' missing in 's' have been filled using 'fill'.
+//
+// Gaps longer than 'maxGap' points are left missing, a 'maxGap' <= 0 means no limit.
+//
+// 'times' must be in chronological order, and are filled in that order, so that 'fill' can
+// rely on values previously filled.
+func Fill(s *Support, times []time.Time, fill Filler, maxGap int) *Support {
+	res := s.Clone()
+	gaps := Gaps(s, times)
+	for _, t := range times {
+		for len(gaps) > 0 && gaps[0].To.Before(t) {
+			gaps = gaps[1:]
+		}
+		if len(gaps) == 0 {
+			break
+		}
+		if g := gaps[0]; t.Before(g.From) || (maxGap > 0 && g.Len > maxGap) || res.has(t) {
+			continue
+		}
+		res.Append(t, fill(res, t))
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+package timeserie_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// TestGaps checks gaps on a simple daily support.
+func TestGaps(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(timeserie.DayDate(2000, 1, 1), 1.0)
+	s.Append(timeserie.DayDate(2000, 1, 4), 4.0)
+	s.Append(timeserie.DayDate(2000, 1, 6), 6.0)
+
+	days := timeserie.Days(timeserie.DayDate(2000, 1, 1), timeserie.DayDate(2000, 1, 7), func(time.Time) bool { return true })
+	gaps := timeserie.Gaps(s, days)
+	if len(gaps) != 2 {
+		t.Fatalf("Gaps() = %v want 2 gaps", gaps)
+	}
+	if g := gaps[0]; g.From != timeserie.DayDate(2000, 1, 2) || g.To != timeserie.DayDate(2000, 1, 3) || g.Len != 2 {
+		t.Errorf("Gaps()[0] = %v want 2000-1-2 to 2000-1-3", g)
+	}
+	if g := gaps[1]; g.From != timeserie.DayDate(2000, 1, 5) || g.To != timeserie.DayDate(2000, 1, 5) || g.Len != 1 {
+		t.Errorf("Gaps()[1] = %v want 2000-1-5", g)
+	}
+	if on := timeserie.GapsOn(s, timeserie.CondDaily); !slices.Equal(on, gaps) {
+		t.Errorf("GapsOn(CondDaily) = %v want %v", on, gaps)
+	}
+	if on := timeserie.GapsOn(s, timeserie.CondMonthday(5)); len(on) != 1 || on[0].Len != 1 {
+		t.Errorf("GapsOn(CondMonthday(5)) = %v want 2000-1-5", on)
+	}
+}
+
+// TestFill checks all fillers on a simple daily support.
+func TestFill(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(timeserie.DayDate(2000, 1, 1), 1.0)
+	s.Append(timeserie.DayDate(2000, 1, 4), 4.0)
+	s.Append(timeserie.DayDate(2000, 1, 6), 6.0)
+	days := timeserie.Every(timeserie.DayDate(2000, 1, 1), timeserie.DayDate(2000, 1, 7), timeserie.Day)
+
+	for _, test := range []struct {
+		name   string
+		fill   timeserie.Filler
+		maxGap int
+		want   []float64
+	}{
+		{"forward", timeserie.FillForward, 0, []float64{1, 1, 1, 4, 4, 6}},
+		{"backward", timeserie.FillBackward, 0, []float64{1, 4, 4, 4, 6, 6}},
+		{"linear", timeserie.FillLinear, 0, []float64{1, 2, 3, 4, 5, 6}},
+		{"constant", timeserie.FillConstant(0), 0, []float64{1, 0, 0, 4, 0, 6}},
+		{"seasonal", timeserie.FillSeasonal(0, 0, 1), 0, []float64{1, 1, 1, 4, 4, 6}},
+		{"maxgap", timeserie.FillLinear, 1, []float64{1, math.NaN(), math.NaN(), 4, 5, 6}},
+	} {
+		x := timeserie.New(timeserie.Fill(s, days, test.fill, test.maxGap), timeserie.ModeNullset)
+		for i, d := range days {
+			got, want := x.F(d), test.want[i]
+			if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+				t.Errorf("Fill(%s).F(%v) = %v want %v", test.name, d, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package timeserie
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Here goes smoothing filters.
+
+// EMA computes a new Support with the exponential moving average of 's'. The weight of a point is
+// halved every 'halfLife', so that irregular gaps between points are accounted for.
+//
+// An error is returned if 'halfLife' is not positive.
+func (s *Support) EMA(halfLife time.Duration) (*Support, error) {
+	if halfLife <= 0 {
+		return nil, fmt.Errorf("EMA requires a positive half-life got %v", halfLife)
+	}
+	res := new(Support)
+	var c float64
+	for i, on := range s.times {
+		if i == 0 {
+			c = s.values[0]
+		} else {
+			alpha := 1 - math.Exp2(-float64(on.Sub(s.times[i-1]))/float64(halfLife))
+			c += alpha * (s.values[i] - c)
+		}
+		res.Append(on, c)
+	}
+	return res, nil
+}
+
+// ScannerEMA returns a Scanner that computes the exponential moving average with a constant
+// smoothing factor 'alpha', regardless of the time between points.
+func ScannerEMA(alpha float64) Scanner {
+	return func(c, v float64) float64 { return c + alpha*(v-c) }
+}
+
+// Kalman computes a new Support with the level estimated by a Kalman filter on a local level model:
+// the level follows a random walk of variance 'q' every 'per', so that irregular gaps between points
+// are accounted for, and is measured with a noise of variance 'r'.
+//
+// An error is returned if 'q' is negative, if 'r' or 'per' is not positive.
+func (s *Support) Kalman(q, r float64, per time.Duration) (*Support, error) {
+	if q < 0 || r <= 0 || per <= 0 {
+		return nil, fmt.Errorf("Kalman requires a non-negative process variance, a positive measurement variance and a positive duration got %v, %v and %v", q, r, per)
+	}
+	res := new(Support)
+	var c, p float64 // estimate and its variance.
+	for i, on := range s.times {
+		if i == 0 {
+			c, p = s.values[0], r
+		} else {
+			p += q * float64(on.Sub(s.times[i-1])) / float64(per)
+			k := p / (p + r)
+			p *= 1 - k
+			c += k * (s.values[i] - c)
+		}
+		res.Append(on, c)
+	}
+	return res, nil
+}
+
+// ScannerKalman returns a Scanner that estimates the level of a local level model, see Kalman,
+// where the level variance grows by 'q' per point, regardless of the time between points.
+//
+// The Scanner holds the estimate variance, it must not be shared between scans. The initial value of
+// the scan is ignored: the first estimate is the first value. An estimate and a measurement without
+// variance keep the estimate.
+func ScannerKalman(q, r float64) Scanner {
+	p := math.Inf(1) // estimate variance.
+	return func(c, v float64) float64 {
+		if math.IsInf(p, 1) {
+			p = r
+			return v
+		}
+		p += q
+		if p+r == 0 {
+			return c
+		}
+		k := p / (p + r)
+		p *= 1 - k
+		return c + k*(v-c)
+	}
+}
+
+// SavitzkyGolay computes a new Support where each value is replaced by the value of the polynomial
+// of 'degree' fitted by least squares on the 'window' closest points. Windows are shifted at the
+// bounds of the support.
+//
+// Points are assumed to be regularly spaced. An error is returned if 'degree' is negative, or if
+// 'window' is not greater than 'degree'.
+func (s *Support) SavitzkyGolay(window, degree int) (*Support, error) {
+	if degree < 0 || window <= degree {
+		return nil, fmt.Errorf("Savitzky-Golay requires a window greater than the degree %v got %v", degree, window)
+	}
+	res := new(Support)
+	n := len(s.times)
+	window = min(window, n)
+	for i, on := range s.times {
+		a := min(max(0, i-window/2), n-window)
+		xs := make([]float64, window)
+		for j := range xs {
+			xs[j] = float64(a + j - i)
+		}
+		// the fitted polynomial value at 0 is its constant coefficient.
+		coefs := polyfit(xs, s.values[a:a+window], nil, degree)
+		res.Append(on, coefs[0])
+	}
+	return res, nil
+}
+
+// LOESS computes a new Support where each value is replaced by a locally weighted linear regression
+// on the 'q' closest points in time, weighted by the tricube of their distance.
+//
+// An error is returned if 'q' is less than 1.
+func (s *Support) LOESS(q int) (*Support, error) { return s.WeightedLOESS(q, nil) }
+
+// WeightedLOESS is like LOESS, with an additional robustness weight per point, in the order of the
+// support. Nil weights are all 1. A point whose neighbours all have a zero weight is unchanged.
+//
+// An error is returned if 'q' is less than 1, or if there is not one weight per point.
+func (s *Support) WeightedLOESS(q int, weights []float64) (*Support, error) {
+	if q < 1 {
+		return nil, fmt.Errorf("LOESS requires at least 1 neighbour got %v", q)
+	}
+	if weights != nil && len(weights) != len(s.times) {
+		return nil, fmt.Errorf("LOESS requires %v weights got %v", len(s.times), len(weights))
+	}
+	res := new(Support)
+	n := len(s.times)
+	q = min(q, n)
+	for i, on := range s.times {
+		// closest points window [a, a+q)
+		a := min(max(0, i-q/2), n-q)
+		for a > 0 && on.Sub(s.times[a-1]) < s.times[a+q-1].Sub(on) {
+			a--
+		}
+		for a+q < n && s.times[a+q].Sub(on) < on.Sub(s.times[a]) {
+			a++
+		}
+		d := float64(max(on.Sub(s.times[a]), s.times[a+q-1].Sub(on)))
+		xs := make([]float64, q)
+		ws := make([]float64, q)
+		var sw float64
+		for j := range xs {
+			xs[j] = float64(s.times[a+j].Sub(on))
+			ws[j] = 1
+			if d > 0 {
+				u := math.Abs(xs[j]) / (1.001 * d)
+				ws[j] = math.Pow(1-u*u*u, 3)
+			}
+			if weights != nil {
+				ws[j] *= weights[a+j]
+			}
+			sw += ws[j]
+			xs[j] /= max(d, 1) // scale for numerical stability.
+		}
+		if sw == 0 {
+			res.Append(on, s.values[i])
+			continue
+		}
+		coefs := polyfit(xs, s.values[a:a+q], ws, 1)
+		res.Append(on, coefs[0])
+	}
+	return res, nil
+}
+
+// polyfit returns the coefficients, constant first, of the polynomial of 'degree' that fits 'ys'
+// over 'xs' by weighted least squares. Nil weights are all 1.
+func polyfit(xs, ys, ws []float64, degree int) []float64 {
+	m := min(degree+1, len(xs))
+	// normal equations: A c = b
+	A := make([][]float64, m)
+	b := make([]float64, m)
+	for k := range A {
+		A[k] = make([]float64, m)
+	}
+	for i, x := range xs {
+		w := 1.0
+		if ws != nil {
+			w = ws[i]
+		}
+		pow := make([]float64, 2*m)
+		pow[0] = w
+		for k := 1; k < len(pow); k++ {
+			pow[k] = pow[k-1] * x
+		}
+		for r := 0; r < m; r++ {
+			b[r] += pow[r] * ys[i]
+			for c := 0; c < m; c++ {
+				A[r][c] += pow[r+c]
+			}
+		}
+	}
+	return solve(A, b)
+}
+
+// solve returns the solution of A x = b using gaussian elimination with partial pivoting. A and b
+// are modified.
+func solve(A [][]float64, b []float64) []float64 {
+	n := len(b)
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(A[i][k]) > math.Abs(A[p][k]) {
+				p = i
+			}
+		}
+		A[k], A[p] = A[p], A[k]
+		b[k], b[p] = b[p], b[k]
+		if A[k][k] == 0 {
+			continue // singular, leave the coefficient to 0.
+		}
+		for i := k + 1; i < n; i++ {
+			f := A[i][k] / A[k][k]
+			for j := k; j < n; j++ {
+				A[i][j] -= f * A[k][j]
+			}
+			b[i] -= f * b[k]
+		}
+	}
+	x := make([]float64, n)
+	for k := n - 1; k >= 0; k-- {
+		if A[k][k] == 0 {
+			continue
+		}
+		x[k] = b[k]
+		for j := k + 1; j < n; j++ {
+			x[k] -= A[k][j] * x[j]
+		}
+		x[k] /= A[k][k]
+	}
+	return x
+}
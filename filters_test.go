@@ -0,0 +1,159 @@
+package timeserie_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// TestSupport_EMA checks that irregular gaps are accounted for.
+func TestSupport_EMA(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(d0, 0.0)
+	s.Append(d1, 1.0)
+	s.Append(d1.Add(2*timeserie.Day), 1.0)
+
+	x, err := s.EMA(timeserie.Day)
+	if err != nil {
+		t.Fatalf("EMA(1 day) error: %v", err)
+	}
+	if _, v := x.At(1); v != 0.5 {
+		t.Errorf("EMA(1 day).At(1) = %v want 0.5", v)
+	}
+	if _, v := x.At(2); v != 0.875 {
+		t.Errorf("EMA(1 day).At(2) = %v want 0.875", v)
+	}
+
+	y := s.Scan(0, timeserie.ScannerEMA(0.5))
+	if _, v := y.At(2); v != 0.75 {
+		t.Errorf("Scan(ScannerEMA(0.5)).At(2) = %v want 0.75", v)
+	}
+}
+
+// TestSupport_Kalman checks that a filter without process noise computes the running mean, and that
+// the process noise grows with the time between points.
+func TestSupport_Kalman(t *testing.T) {
+	s := new(timeserie.Support)
+	for i, v := range []float64{1, 3, 2, 6} {
+		s.Append(timeserie.DayDate(2000, 1, 1+i), v)
+	}
+	x, err := s.Kalman(0, 1, timeserie.Day)
+	if err != nil {
+		t.Fatalf("Kalman(0, 1, 1 day) error: %v", err)
+	}
+	for i, want := range []float64{1, 2, 2, 3} {
+		if _, v := x.At(i); math.Abs(v-want) > 1e-12 {
+			t.Errorf("Kalman(0, 1, 1 day).At(%v) = %v want %v", i, v, want)
+		}
+	}
+
+	// after one day the level variance is 1+1, after three days 1+3.
+	for _, test := range []struct {
+		gap  int
+		want float64
+	}{{1, 1 + 2.0/3*2}, {3, 1 + 4.0/5*2}} {
+		s := new(timeserie.Support)
+		s.Append(d0, 1)
+		s.Append(d0.AddDate(0, 0, test.gap), 3)
+		x, err := s.Kalman(1, 1, timeserie.Day)
+		if err != nil {
+			t.Fatalf("Kalman(1, 1, 1 day) error: %v", err)
+		}
+		if _, v := x.At(1); math.Abs(v-test.want) > 1e-12 {
+			t.Errorf("Kalman(1, 1, 1 day) after %v days = %v want %v", test.gap, v, test.want)
+		}
+	}
+	if y := s.Scan(0, timeserie.ScannerKalman(0, 0)); y.Len() != s.Len() {
+		t.Errorf("Scan(ScannerKalman(0, 0)) has %v points want %v", y.Len(), s.Len())
+	}
+}
+
+// TestSupport_SavitzkyGolay checks that a polynomial of the same degree is preserved.
+func TestSupport_SavitzkyGolay(t *testing.T) {
+	s := new(timeserie.Support)
+	for i := 0; i < 10; i++ {
+		s.Append(timeserie.DayDate(2000, 1, 1+i), float64(i*i-3*i))
+	}
+	x, err := s.SavitzkyGolay(5, 2)
+	if err != nil {
+		t.Fatalf("SavitzkyGolay(5, 2) error: %v", err)
+	}
+	for i := 0; i < x.Len(); i++ {
+		_, got := x.At(i)
+		_, want := s.At(i)
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("SavitzkyGolay(5, 2).At(%v) = %v want %v", i, got, want)
+		}
+	}
+}
+
+// TestSupport_LOESS checks that a line is preserved, with irregular gaps.
+func TestSupport_LOESS(t *testing.T) {
+	s := new(timeserie.Support)
+	for _, i := range []int{0, 1, 2, 5, 6, 10, 11, 12} {
+		on := d0.Add(time.Duration(i) * time.Hour)
+		s.Append(on, 2*float64(i)+1)
+	}
+	x, err := s.LOESS(4)
+	if err != nil {
+		t.Fatalf("LOESS(4) error: %v", err)
+	}
+	for i := 0; i < x.Len(); i++ {
+		_, got := x.At(i)
+		_, want := s.At(i)
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("LOESS(4).At(%v) = %v want %v", i, got, want)
+		}
+	}
+}
+
+// TestSupport_WeightedLOESS checks that a point with a zero weight does not leak into its neighbours.
+func TestSupport_WeightedLOESS(t *testing.T) {
+	s := new(timeserie.Support)
+	weights := make([]float64, 10)
+	for i := range weights {
+		v := 2*float64(i) + 1
+		weights[i] = 1
+		if i == 4 {
+			v, weights[i] = 100, 0
+		}
+		s.Append(d0.Add(time.Duration(i)*time.Hour), v)
+	}
+	x, err := s.WeightedLOESS(5, weights)
+	if err != nil {
+		t.Fatalf("WeightedLOESS(5) error: %v", err)
+	}
+	for i := 0; i < x.Len(); i++ {
+		if _, got := x.At(i); math.Abs(got-(2*float64(i)+1)) > 1e-9 {
+			t.Errorf("WeightedLOESS(5).At(%v) = %v want %v", i, got, 2*float64(i)+1)
+		}
+	}
+}
+
+// TestSupport_filtersInvalid checks that invalid windows are rejected instead of panicking.
+func TestSupport_filtersInvalid(t *testing.T) {
+	s := new(timeserie.Support)
+	for i := 0; i < 10; i++ {
+		s.Append(timeserie.DayDate(2000, 1, 1+i), float64(i))
+	}
+	for name, err := range map[string]error{
+		"EMA(0)":                second(s.EMA(0)),
+		"Kalman(0, 0, 1 day)":   second(s.Kalman(0, 0, timeserie.Day)),
+		"Kalman(-1, 1, 1 day)":  second(s.Kalman(-1, 1, timeserie.Day)),
+		"Kalman(1, 1, 0)":       second(s.Kalman(1, 1, 0)),
+		"SavitzkyGolay(0, 2)":   second(s.SavitzkyGolay(0, 2)),
+		"SavitzkyGolay(-3, 0)":  second(s.SavitzkyGolay(-3, 0)),
+		"SavitzkyGolay(3, 3)":   second(s.SavitzkyGolay(3, 3)),
+		"LOESS(0)":              second(s.LOESS(0)),
+		"WeightedLOESS(3, [1])": second(s.WeightedLOESS(3, []float64{1})),
+	} {
+		if err == nil {
+			t.Errorf("%s want error", name)
+		}
+	}
+}
+
+// second returns the error of a function returning a value and an error.
+func second[T any](_ T, err error) error { return err }
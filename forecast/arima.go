@@ -0,0 +1,325 @@
+package forecast
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// ARIMA is an autoregressive integrated moving average model fitted on a regularly spaced support.
+//
+// The differenced series x follows
+//
+//	x[t] = Const + AR[0]*x[t-1] + ... + AR[p-1]*x[t-p] + e[t] + MA[0]*e[t-1] + ... + MA[q-1]*e[t-q]
+//
+// where e is a white noise of variance Sigma2.
+type ARIMA struct {
+	P, D, Q int
+	Const   float64
+	AR, MA  []float64
+	Sigma2  float64 // Variance of the residuals.
+
+	last      time.Time
+	next      func(time.Time) time.Time // time of the point after a time of the support.
+	levels    [][]float64               // series differenced 0 to D times.
+	times     []time.Time               // times of the differenced series.
+	residuals []float64                 // residuals of the differenced series.
+}
+
+// AR returns an autoregressive model of order 'p' estimated with the Yule-Walker equations.
+func AR(s *timeserie.Support, p int) (*ARIMA, error) {
+	m, err := newARIMA(s, p, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	x := m.levels[0]
+	mu := mean(x)
+	phi, err := yuleWalker(x, p)
+	if err != nil {
+		return nil, err
+	}
+	m.AR = phi
+	m.Const = mu
+	for _, a := range phi {
+		m.Const -= a * mu
+	}
+	m.update()
+	return m, nil
+}
+
+// MA returns a moving average model of order 'q' estimated by conditional least squares.
+func MA(s *timeserie.Support, q int) (*ARIMA, error) { return FitARIMA(s, 0, 0, q) }
+
+// FitARIMA returns an ARIMA(p,d,q) model estimated by conditional least squares on the support
+// differenced 'd' times.
+//
+// The support must be regularly spaced, by a constant duration or by a calendar period of
+// timeserie.Periods, like the first day of each month: an error is returned otherwise.
+func FitARIMA(s *timeserie.Support, p, d, q int) (*ARIMA, error) {
+	m, err := newARIMA(s, p, d, q)
+	if err != nil {
+		return nil, err
+	}
+	x := m.levels[d]
+	// Start from the Yule-Walker estimates.
+	mu := mean(x)
+	phi, err := yuleWalker(x, p)
+	if err != nil {
+		return nil, err
+	}
+	c := mu
+	for _, a := range phi {
+		c -= a * mu
+	}
+	x0 := append([]float64{c}, phi...)
+	x0 = append(x0, make([]float64, q)...)
+
+	params := func(x []float64) {
+		m.Const, m.AR, m.MA = x[0], x[1:1+p], x[1+p:]
+	}
+	best := minimize(func(x []float64) float64 {
+		params(x)
+		return m.sse()
+	}, x0)
+	params(best)
+	m.update()
+	return m, nil
+}
+
+// yuleWalker returns the AR coefficients of order 'p' of 'x' estimated with the Yule-Walker
+// equations.
+func yuleWalker(x []float64, p int) ([]float64, error) {
+	acf, err := timeserie.Autocorrelations(x, p)
+	if err != nil {
+		return nil, err
+	}
+	phi, _, err := timeserie.YuleWalker(acf)
+	return phi, err
+}
+
+// newARIMA creates an empty model with the differenced support 's'.
+func newARIMA(s *timeserie.Support, p, d, q int) (*ARIMA, error) {
+	if p < 0 || d < 0 || q < 0 {
+		return nil, fmt.Errorf("invalid ARIMA order (%v,%v,%v)", p, d, q)
+	}
+	if s.Len()-d <= 2*(p+q+1) {
+		return nil, fmt.Errorf("ARIMA(%v,%v,%v) requires more than %v points got %v", p, d, q, 2*(p+q+1)+d, s.Len())
+	}
+	m := &ARIMA{P: p, D: d, Q: q}
+	m.last, _ = s.At(s.Len() - 1)
+	var err error
+	if m.next, err = spacing(s); err != nil {
+		return nil, err
+	}
+	for i := 0; i <= d; i++ {
+		m.levels = append(m.levels, valuesOf(s))
+		if i == d {
+			for t := range s.Times() {
+				m.times = append(m.times, t)
+			}
+		}
+		s = s.Delta()
+	}
+	return m, nil
+}
+
+// spacing returns the function that gives the time after a time of the regularly spaced support
+// 's', with at least two points.
+func spacing(s *timeserie.Support) (func(time.Time) time.Time, error) {
+	times := slices.Collect(s.Times())
+	step := times[1].Sub(times[0])
+	regular := true
+	for i := 2; i < len(times) && regular; i++ {
+		regular = times[i].Sub(times[i-1]) == step
+	}
+	if regular {
+		return func(t time.Time) time.Time { return t.Add(step) }, nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(timeserie.Periods)) {
+		cond := timeserie.Periods[name]
+		days := timeserie.Days(times[0], times[len(times)-1].Add(time.Nanosecond), cond)
+		if slices.EqualFunc(days, times, time.Time.Equal) {
+			return func(t time.Time) time.Time {
+				t = t.Add(timeserie.Day)
+				for !cond(t) {
+					t = t.Add(timeserie.Day)
+				}
+				return t
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("ARIMA requires a support spaced by a constant duration or a calendar period")
+}
+
+// sse computes residuals and returns the sum of squared residuals.
+func (m *ARIMA) sse() float64 {
+	x := m.levels[m.D]
+	m.residuals = m.residuals[:0]
+	var sse float64
+	for t := range x {
+		if t < m.P {
+			m.residuals = append(m.residuals, 0)
+			continue
+		}
+		e := x[t] - m.predict(x, m.residuals, t)
+		m.residuals = append(m.residuals, e)
+		sse += e * e
+	}
+	return sse
+}
+
+// predict returns the one-step prediction of x[t] given previous values and residuals.
+func (m *ARIMA) predict(x, e []float64, t int) float64 {
+	y := m.Const
+	for i, a := range m.AR {
+		y += a * x[t-1-i]
+	}
+	for j, b := range m.MA {
+		if t-1-j >= 0 {
+			y += b * e[t-1-j]
+		}
+	}
+	return y
+}
+
+// update computes the residuals and their variance.
+func (m *ARIMA) update() { m.Sigma2 = m.sse() / float64(m.observations()) }
+
+// observations returns the number of residuals used in the estimation.
+func (m *ARIMA) observations() int { return len(m.levels[m.D]) - m.P }
+
+// params returns the number of estimated parameters, including the constant and the variance.
+func (m *ARIMA) params() int { return m.P + m.Q + 2 }
+
+// logLikelihood returns the conditional gaussian log-likelihood.
+func (m *ARIMA) logLikelihood() float64 {
+	n := float64(m.observations())
+	return -n / 2 * (math.Log(2*math.Pi*m.Sigma2) + 1)
+}
+
+// AIC returns the Akaike information criterion of the model.
+func (m *ARIMA) AIC() float64 { return -2*m.logLikelihood() + 2*float64(m.params()) }
+
+// BIC returns the Bayesian information criterion of the model.
+func (m *ARIMA) BIC() float64 {
+	return -2*m.logLikelihood() + float64(m.params())*math.Log(float64(m.observations()))
+}
+
+// Residuals returns the residuals of the model on the differenced support.
+func (m *ARIMA) Residuals() *timeserie.Support {
+	s := new(timeserie.Support)
+	for t := m.P; t < len(m.residuals); t++ {
+		s.Append(m.times[t], m.residuals[t])
+	}
+	return s
+}
+
+// LjungBox returns the Ljung-Box statistic of the residuals up to 'lags', and its p-value.
+//
+// A small p-value means the residuals are not a white noise, and the model is not adequate.
+func (m *ARIMA) LjungBox(lags int) (q, pvalue float64) {
+	e := valuesOf(m.Residuals())
+	return LjungBox(e, lags, m.P+m.Q)
+}
+
+// LjungBox returns the Ljung-Box statistic of 'values' up to 'lags', and its p-value for a
+// chi-squared distribution with 'lags'-'fitted' degrees of freedom. Both are NaN if 'lags' is
+// negative.
+func LjungBox(values []float64, lags, fitted int) (q, pvalue float64) {
+	n := float64(len(values))
+	acf, err := timeserie.Autocorrelations(values, lags)
+	if err != nil {
+		return math.NaN(), math.NaN()
+	}
+	for k := 1; k <= lags; k++ {
+		q += acf[k] * acf[k] / (n - float64(k))
+	}
+	q *= n * (n + 2)
+	df := lags - fitted
+	if df <= 0 {
+		return q, math.NaN()
+	}
+	return q, 1 - gammaP(float64(df)/2, q/2)
+}
+
+// Forecast returns the forecast of the next 'h' points, spaced like the fitted support.
+func (m *ARIMA) Forecast(h int) *timeserie.Support {
+	// Extend all levels with forecasts, starting from the most differenced one.
+	x := append([]float64(nil), m.levels[m.D]...)
+	e := append([]float64(nil), m.residuals...)
+	n := len(x)
+	for t := n; t < n+h; t++ {
+		x = append(x, m.predict(x, e, t))
+		e = append(e, 0)
+	}
+	forecasts := x[n:]
+	for d := m.D - 1; d >= 0; d-- {
+		last := m.levels[d][len(m.levels[d])-1]
+		integrated := make([]float64, h)
+		for i, v := range forecasts {
+			last += v
+			integrated[i] = last
+		}
+		forecasts = integrated
+	}
+	s := new(timeserie.Support)
+	t := m.last
+	for _, v := range forecasts {
+		t = m.next(t)
+		s.Append(t, v)
+	}
+	return s
+}
+
+// gammaP returns the regularized lower incomplete gamma function P(a, x).
+func gammaP(a, x float64) float64 {
+	const (
+		maxIter = 500
+		eps     = 1e-14
+	)
+	if x <= 0 {
+		return 0
+	}
+	lg, _ := math.Lgamma(a)
+	if x < a+1 {
+		// series representation.
+		sum, term := 1/a, 1/a
+		for n := 1; n < maxIter; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*eps {
+				break
+			}
+		}
+		return sum * math.Exp(-x+a*math.Log(x)-lg)
+	}
+	// continued fraction representation (modified Lentz).
+	tiny := 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < maxIter; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return 1 - math.Exp(-x+a*math.Log(x)-lg)*h
+}
@@ -0,0 +1,117 @@
+package forecast_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/forecast"
+)
+
+// ar1 returns a daily AR(1) process x[t] = c + phi*x[t-1] + e[t] with 'n' points.
+func ar1(n int, c, phi float64) func(i int) float64 {
+	r := rand.New(rand.NewPCG(1, 2))
+	x := make([]float64, n)
+	prev := c / (1 - phi)
+	for i := range x {
+		prev = c + phi*prev + r.NormFloat64()
+		x[i] = prev
+	}
+	return func(i int) float64 { return x[i] }
+}
+
+// TestAR checks the Yule-Walker estimation of an AR(1) process.
+func TestAR(t *testing.T) {
+	s := series(2000, ar1(2000, 1, 0.7))
+	m, err := forecast.AR(s, 1)
+	if err != nil {
+		t.Fatalf("AR() error: %v", err)
+	}
+	if math.Abs(m.AR[0]-0.7) > 0.05 {
+		t.Errorf("AR().AR[0] = %v want 0.7", m.AR[0])
+	}
+	if math.Abs(m.Sigma2-1) > 0.1 {
+		t.Errorf("AR().Sigma2 = %v want 1", m.Sigma2)
+	}
+	if _, p := m.LjungBox(10); p < 0.01 {
+		t.Errorf("AR().LjungBox() p-value = %v want white noise", p)
+	}
+}
+
+// TestFitARIMA checks the conditional least square estimation and the order selection.
+func TestFitARIMA(t *testing.T) {
+	s := series(2000, ar1(2000, 1, 0.7))
+	m1, err := forecast.FitARIMA(s, 1, 0, 0)
+	if err != nil {
+		t.Fatalf("FitARIMA(1,0,0) error: %v", err)
+	}
+	if math.Abs(m1.AR[0]-0.7) > 0.05 || math.Abs(m1.Const-1) > 0.2 {
+		t.Errorf("FitARIMA(1,0,0) = %v, %v want 0.7, 1", m1.AR[0], m1.Const)
+	}
+	m0, err := forecast.MA(s, 1)
+	if err != nil {
+		t.Fatalf("MA(1) error: %v", err)
+	}
+	if m0.AIC() <= m1.AIC() || m0.BIC() <= m1.BIC() {
+		t.Errorf("MA(1) AIC=%v BIC=%v want more than AR(1) AIC=%v BIC=%v", m0.AIC(), m0.BIC(), m1.AIC(), m1.BIC())
+	}
+	if _, p := m0.LjungBox(10); p > 0.01 {
+		t.Errorf("MA(1).LjungBox() p-value = %v want correlated residuals", p)
+	}
+}
+
+// TestFitARIMA_irregular checks that an irregularly spaced support is rejected.
+func TestFitARIMA_irregular(t *testing.T) {
+	s := series(100, ar1(100, 1, 0.7))
+	s.Delete(timeserie.DayDate(2000, 1, 10))
+	if _, err := forecast.FitARIMA(s, 1, 0, 0); err == nil {
+		t.Errorf("FitARIMA(irregular) want error")
+	}
+}
+
+// TestFitARIMA_monthly checks that a calendar-regular support is accepted, and forecast on the same
+// calendar.
+func TestFitARIMA_monthly(t *testing.T) {
+	s := new(timeserie.Support)
+	for i := 0; i < 30; i++ {
+		s.Append(timeserie.DayDate(2000, time.Month(1+i), 1), 3+2*float64(i))
+	}
+	m, err := forecast.FitARIMA(s, 0, 1, 0)
+	if err != nil {
+		t.Fatalf("FitARIMA(monthly) error: %v", err)
+	}
+	f := m.Forecast(2)
+	for i, want := range []time.Time{timeserie.DayDate(2002, 7, 1), timeserie.DayDate(2002, 8, 1)} {
+		if on, _ := f.At(i); !on.Equal(want) {
+			t.Errorf("Forecast(2).At(%v) = %v want %v", i, on, want)
+		}
+	}
+}
+
+// TestAR_infinite checks that the Yule-Walker error is returned.
+func TestAR_infinite(t *testing.T) {
+	s := series(20, func(i int) float64 { return float64(i % 3) })
+	s.Upsert(timeserie.DayDate(2000, 1, 5), math.Inf(1))
+	if _, err := forecast.AR(s, 1); err == nil {
+		t.Errorf("AR(infinite value) want error")
+	}
+}
+
+// TestARIMA_Forecast checks that an integrated model extrapolates a trend.
+func TestARIMA_Forecast(t *testing.T) {
+	s := series(50, func(i int) float64 { return 3 + 2*float64(i) })
+	m, err := forecast.FitARIMA(s, 0, 1, 0)
+	if err != nil {
+		t.Fatalf("FitARIMA(0,1,0) error: %v", err)
+	}
+	f := m.Forecast(3)
+	want := next(s, 3)
+	for i := 0; i < f.Len(); i++ {
+		on, v := f.At(i)
+		if on != want[i] || math.Abs(v-(3+2*float64(50+i))) > 1e-6 {
+			t.Errorf("Forecast(3)[%v] = %v, %v want %v, %v", i, on, v, want[i], 3+2*float64(50+i))
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package forecast
+
+import (
+	"math"
+	"slices"
+)
+
+// minimize returns the point that minimizes 'f' starting from 'x0', using the Nelder-Mead simplex
+// method.
+func minimize(f func(x []float64) float64, x0 []float64) []float64 {
+	const (
+		maxIter = 2000
+		tol     = 1e-10
+	)
+	n := len(x0)
+	// initial simplex: x0 and one step in each direction.
+	simplex := make([][]float64, n+1)
+	values := make([]float64, n+1)
+	for i := range simplex {
+		x := slices.Clone(x0)
+		if i > 0 {
+			x[i-1] += 0.5
+		}
+		simplex[i], values[i] = x, f(x)
+	}
+	order := make([]int, n+1)
+	point := func(c []float64, a float64, x []float64) []float64 {
+		res := make([]float64, n)
+		for i := range res {
+			res[i] = c[i] + a*(x[i]-c[i])
+		}
+		return res
+	}
+	for iter := 0; iter < maxIter; iter++ {
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortFunc(order, func(a, b int) int { return cmpFloat(values[a], values[b]) })
+		best, worst, second := order[0], order[n], order[max(n-1, 0)]
+		if math.Abs(values[worst]-values[best]) <= tol*(math.Abs(values[best])+tol) {
+			break
+		}
+		// centroid of all but the worst.
+		c := make([]float64, n)
+		for _, i := range order[:n] {
+			for j := range c {
+				c[j] += simplex[i][j] / float64(n)
+			}
+		}
+		r := point(c, -1, simplex[worst])
+		fr := f(r)
+		switch {
+		case fr < values[best]:
+			e := point(c, -2, simplex[worst])
+			if fe := f(e); fe < fr {
+				simplex[worst], values[worst] = e, fe
+			} else {
+				simplex[worst], values[worst] = r, fr
+			}
+		case fr < values[second]:
+			simplex[worst], values[worst] = r, fr
+		default:
+			k := point(c, 0.5, simplex[worst])
+			if fk := f(k); fk < values[worst] {
+				simplex[worst], values[worst] = k, fk
+				continue
+			}
+			// shrink toward the best.
+			for _, i := range order[1:] {
+				simplex[i] = point(simplex[best], 0.5, simplex[i])
+				values[i] = f(simplex[i])
+			}
+		}
+	}
+	best := 0
+	for i, v := range values {
+		if v < values[best] {
+			best = i
+		}
+	}
+	return simplex[best]
+}
+
+// cmpFloat compares floats, NaN being greater than any other value.
+func cmpFloat(a, b float64) int {
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b):
+		return 0
+	case math.IsNaN(a) || a > b:
+		return 1
+	case math.IsNaN(b) || a < b:
+		return -1
+	}
+	return 0
+}
+
+// logistic maps any real to ]0,1[.
+func logistic(x float64) float64 { return 1 / (1 + math.Exp(-x)) }
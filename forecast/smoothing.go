@@ -0,0 +1,244 @@
+// Package forecast computes forecasts of regularly spaced timeserie Supports.
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// Method is the kind of exponential smoothing.
+type Method int
+
+const (
+	Simple                    Method = iota // Simple exponential smoothing: level only.
+	Holt                                    // Holt's linear trend: level and trend.
+	HoltWintersAdditive                     // Holt-Winters: level, trend and additive seasonality.
+	HoltWintersMultiplicative               // Holt-Winters: level, trend and multiplicative seasonality.
+)
+
+// Smoothing is an exponential smoothing model fitted on a support.
+type Smoothing struct {
+	Method             Method
+	Alpha, Beta, Gamma float64 // Smoothing parameters for the level, trend and season.
+	Period             int     // Number of points in a season.
+
+	level, trend float64
+	season       []float64
+	n            int     // number of fitted points.
+	sse          float64 // sum of squared one-step errors.
+	errors       int     // number of one-step errors.
+}
+
+// NewSmoothing returns a model computed on 's' with the given smoothing parameters.
+//
+// 'period' is only used by Holt-Winters methods, and parameters that are not used by the method are
+// ignored.
+func NewSmoothing(s *timeserie.Support, method Method, period int, alpha, beta, gamma float64) (*Smoothing, error) {
+	values := valuesOf(s)
+	if err := check(values, method, period); err != nil {
+		return nil, err
+	}
+	m := &Smoothing{Method: method, Alpha: alpha, Beta: beta, Gamma: gamma, Period: period}
+	m.run(values)
+	return m, nil
+}
+
+// FitSmoothing returns the model on 's' whose parameters minimize the sum of squared one-step
+// errors.
+func FitSmoothing(s *timeserie.Support, method Method, period int) (*Smoothing, error) {
+	values := valuesOf(s)
+	if err := check(values, method, period); err != nil {
+		return nil, err
+	}
+	params := []int{1, 2, 3, 3}[method] // number of parameters to fit.
+	model := func(x []float64) *Smoothing {
+		p := make([]float64, 3)
+		for i := range x {
+			p[i] = logistic(x[i])
+		}
+		m := &Smoothing{Method: method, Alpha: p[0], Beta: p[1], Gamma: p[2], Period: period}
+		m.run(values)
+		return m
+	}
+	x := minimize(func(x []float64) float64 { return model(x).sse }, make([]float64, params))
+	return model(x), nil
+}
+
+// check returns an error if there are not enough points for the method, or if a multiplicative
+// season is used on non-positive values.
+func check(values []float64, method Method, period int) error {
+	n := len(values)
+	switch method {
+	case Simple:
+		if n < 2 {
+			return fmt.Errorf("simple exponential smoothing requires at least 2 points got %v", n)
+		}
+	case Holt:
+		if n < 3 {
+			return fmt.Errorf("holt's linear trend requires at least 3 points got %v", n)
+		}
+	case HoltWintersAdditive, HoltWintersMultiplicative:
+		if period < 2 {
+			return fmt.Errorf("holt-winters requires a period of at least 2 got %v", period)
+		}
+		if n < 2*period+1 {
+			return fmt.Errorf("holt-winters requires at least two periods and one point got %v points for period %v", n, period)
+		}
+		if method == HoltWintersMultiplicative && slices.ContainsFunc(values, func(v float64) bool { return !(v > 0) }) {
+			return fmt.Errorf("multiplicative holt-winters requires positive values")
+		}
+	default:
+		return fmt.Errorf("unknown smoothing method %v", method)
+	}
+	return nil
+}
+
+// valuesOf returns the values in 's'.
+func valuesOf(s *timeserie.Support) []float64 {
+	values := make([]float64, 0, s.Len())
+	for _, v := range s.Values() {
+		values = append(values, v)
+	}
+	return values
+}
+
+// multiplicative returns true if the model has a multiplicative season.
+func (m *Smoothing) multiplicative() bool { return m.Method == HoltWintersMultiplicative }
+
+// seasonal returns true if the model has a season.
+func (m *Smoothing) seasonal() bool {
+	return m.Method == HoltWintersAdditive || m.Method == HoltWintersMultiplicative
+}
+
+// run initializes the model states and smooth all 'values'.
+func (m *Smoothing) run(values []float64) {
+	m.n, m.sse, m.errors = len(values), 0, 0
+	m.trend = 0
+	var start int
+	switch m.Method {
+	case Simple:
+		m.level, start = values[0], 1
+	case Holt:
+		m.level, m.trend, start = values[0], values[1]-values[0], 1
+	default:
+		p := m.Period
+		first, second := mean(values[:p]), mean(values[p:2*p])
+		m.trend = (second - first) / float64(p)
+		m.season = make([]float64, p)
+		for i, v := range values[:p] {
+			// deseasonalized level at 'i' is first + (i - (p-1)/2) * trend
+			l := first + (float64(i)-float64(p-1)/2)*m.trend
+			if m.multiplicative() {
+				m.season[i] = v / l
+			} else {
+				m.season[i] = v - l
+			}
+		}
+		m.level = first + float64(p-1)/2*m.trend
+		start = p
+	}
+	for t := start; t < len(values); t++ {
+		y := values[t]
+		e := y - m.predict(t, 1)
+		m.sse += e * e
+		m.errors++
+
+		prev := m.level
+		var s float64
+		switch {
+		case m.multiplicative():
+			s = m.season[t%m.Period]
+			m.level = m.Alpha*y/s + (1-m.Alpha)*(prev+m.trend)
+		case m.seasonal():
+			s = m.season[t%m.Period]
+			m.level = m.Alpha*(y-s) + (1-m.Alpha)*(prev+m.trend)
+		default:
+			m.level = m.Alpha*y + (1-m.Alpha)*(prev+m.trend)
+		}
+		if m.Method != Simple {
+			m.trend = m.Beta*(m.level-prev) + (1-m.Beta)*m.trend
+		}
+		switch {
+		case m.multiplicative():
+			m.season[t%m.Period] = m.Gamma*y/m.level + (1-m.Gamma)*s
+		case m.seasonal():
+			m.season[t%m.Period] = m.Gamma*(y-m.level) + (1-m.Gamma)*s
+		}
+	}
+}
+
+// predict returns the forecast for point 't', computed 'h' steps after the current state.
+func (m *Smoothing) predict(t, h int) float64 {
+	l := m.level + float64(h)*m.trend
+	switch {
+	case m.multiplicative():
+		return l * m.season[t%m.Period]
+	case m.seasonal():
+		return l + m.season[t%m.Period]
+	}
+	return l
+}
+
+// SSE returns the sum of squared one-step errors on the fitted support.
+func (m *Smoothing) SSE() float64 { return m.sse }
+
+// Sigma returns the standard deviation of the one-step errors on the fitted support.
+func (m *Smoothing) Sigma() float64 { return math.Sqrt(m.sse / float64(m.errors)) }
+
+// variance returns the forecast variance 'h' steps ahead, relative to the one-step error variance.
+//
+// Multiplicative seasonality uses the additive approximation.
+func (m *Smoothing) variance(h int) float64 {
+	a, b, g := m.Alpha, m.Beta, m.Gamma
+	fh := float64(h)
+	switch m.Method {
+	case Simple:
+		return 1 + (fh-1)*a*a
+	case Holt:
+		return 1 + (fh-1)*(a*a+a*b*fh+b*b*fh*(2*fh-1)/6)
+	}
+	k := float64((h - 1) / m.Period)
+	p := float64(m.Period)
+	return 1 + (fh-1)*(a*a+a*b*fh+b*b*fh*(2*fh-1)/6) + g*k*(2*a+g+b*p*(k+1))
+}
+
+// Forecast returns the forecast at 'times', and the lower and upper bounds of the prediction
+// interval with probability 'level' (e.g. 0.95).
+//
+// 'times' are the next points after the fitted support, in chronological order, usually generated
+// by timeserie.Days or timeserie.Every with the same spacing as the fitted support.
+//
+// An error is returned if 'level' is not in (0, 1).
+func (m *Smoothing) Forecast(times []time.Time, level float64) (mean, lower, upper *timeserie.Support, err error) {
+	if !(level > 0 && level < 1) {
+		return nil, nil, nil, fmt.Errorf("prediction interval requires a level in (0, 1) got %v", level)
+	}
+	mean, lower, upper = new(timeserie.Support), new(timeserie.Support), new(timeserie.Support)
+	z := quantile(level)
+	sigma := m.Sigma()
+	for i, t := range times {
+		h := i + 1
+		y := m.predict(m.n+i, h)
+		d := z * sigma * math.Sqrt(m.variance(h))
+		mean.Append(t, y)
+		lower.Append(t, y-d)
+		upper.Append(t, y+d)
+	}
+	return mean, lower, upper, nil
+}
+
+// quantile returns the two-sided standard normal quantile for probability 'level'.
+func quantile(level float64) float64 { return math.Sqrt2 * math.Erfinv(level) }
+
+// mean returns the arithmetic mean of 'values'.
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
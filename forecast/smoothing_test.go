@@ -0,0 +1,92 @@
+package forecast_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/forecast"
+)
+
+// series returns a daily support starting on 2000-1-1 with 'n' values computed by 'f'.
+func series(n int, f func(i int) float64) *timeserie.Support {
+	s := new(timeserie.Support)
+	for i := 0; i < n; i++ {
+		s.Append(timeserie.DayDate(2000, 1, 1+i), f(i))
+	}
+	return s
+}
+
+// next returns 'n' daily times after the support 's'.
+func next(s *timeserie.Support, n int) []time.Time {
+	last, _ := s.At(s.Len() - 1)
+	return timeserie.Every(last.Add(timeserie.Day), last.Add(time.Duration(n+1)*timeserie.Day), timeserie.Day)
+}
+
+// TestFitSmoothing checks that each method forecasts the series it models.
+func TestFitSmoothing(t *testing.T) {
+	const season = 7
+	for _, test := range []struct {
+		name   string
+		method forecast.Method
+		f      func(i int) float64
+	}{
+		{"simple", forecast.Simple, func(i int) float64 { return 10 }},
+		{"holt", forecast.Holt, func(i int) float64 { return 10 + 2*float64(i) }},
+		{"additive", forecast.HoltWintersAdditive, func(i int) float64 { return 10 + 0.5*float64(i) + []float64{1, 2, 3, 0, -1, -2, -3}[i%season] }},
+		{"multiplicative", forecast.HoltWintersMultiplicative, func(i int) float64 { return (100 + float64(i)) * []float64{1.1, 1.2, 1, 0.9, 0.8, 1, 1}[i%season] }},
+	} {
+		s := series(8*season, test.f)
+		m, err := forecast.FitSmoothing(s, test.method, season)
+		if err != nil {
+			t.Fatalf("FitSmoothing(%s) error: %v", test.name, err)
+		}
+		mean, lower, upper, err := m.Forecast(next(s, season), 0.95)
+		if err != nil {
+			t.Fatalf("FitSmoothing(%s).Forecast() error: %v", test.name, err)
+		}
+		for i := 0; i < mean.Len(); i++ {
+			on, got := mean.At(i)
+			want := test.f(s.Len() + i)
+			if math.Abs(got-want) > 1e-3*math.Abs(want) {
+				t.Errorf("FitSmoothing(%s).Forecast(%v) = %v want %v", test.name, on, got, want)
+			}
+			if _, l := lower.At(i); l > got {
+				t.Errorf("FitSmoothing(%s).Forecast(%v) lower = %v > %v", test.name, on, l, got)
+			}
+			if _, u := upper.At(i); u < got {
+				t.Errorf("FitSmoothing(%s).Forecast(%v) upper = %v < %v", test.name, on, u, got)
+			}
+		}
+	}
+}
+
+// TestNewSmoothing checks the interval width grows with the horizon.
+func TestNewSmoothing(t *testing.T) {
+	s := series(20, func(i int) float64 { return float64(i % 2) })
+	m, err := forecast.NewSmoothing(s, forecast.Simple, 0, 0.5, 0, 0)
+	if err != nil {
+		t.Fatalf("NewSmoothing() error: %v", err)
+	}
+	_, lower, upper, err := m.Forecast(next(s, 3), 0.95)
+	if err != nil {
+		t.Fatalf("Forecast() error: %v", err)
+	}
+	width := func(i int) float64 { _, l := lower.At(i); _, u := upper.At(i); return u - l }
+	if !(width(0) < width(1) && width(1) < width(2)) {
+		t.Errorf("Forecast() widths = %v, %v, %v want increasing", width(0), width(1), width(2))
+	}
+
+	if _, err := forecast.NewSmoothing(series(10, func(int) float64 { return 1 }), forecast.HoltWintersAdditive, 7, 0.5, 0.5, 0.5); err == nil {
+		t.Errorf("NewSmoothing(too short) error = nil want error")
+	}
+	if _, err := forecast.NewSmoothing(series(21, func(i int) float64 { return float64(i % 7) }), forecast.HoltWintersMultiplicative, 7, 0.5, 0.5, 0.5); err == nil {
+		t.Errorf("NewSmoothing(multiplicative with zeros) error = nil want error")
+	}
+	for _, level := range []float64{0, 1, -0.5, 95} {
+		if _, _, _, err := m.Forecast(next(s, 3), level); err == nil {
+			t.Errorf("Forecast(level %v) error = nil want error", level)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package timeserie
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Here goes alternative formats of the value change dump: CSV and binary.
+
+// FormatCSV writes supports as CSV: a header with the "on" column and a column per series in
+// alphabetical order, then a row per time. Missing values are empty cells.
+func FormatCSV(w io.Writer, dict map[string]*Support) error {
+	fr := FrameOf(dict)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{attrOn}, fr.Names()...)); err != nil {
+		return err
+	}
+	for on, row := range fr.Rows() {
+		record := []string{formatTime(on)}
+		for _, v := range row {
+			cell := ""
+			if !math.IsNaN(v) {
+				cell = strconv.FormatFloat(v, 'g', -1, 64)
+			}
+			record = append(record, cell)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// LoadCSV loads supports from CSV, as written by FormatCSV.
+func LoadCSV(dict map[string]*Support, r io.Reader) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("load csv error: cannot read header: %w", err)
+	}
+	if len(header) == 0 || header[0] != attrOn {
+		return fmt.Errorf("load csv error: first column must be %q got %q", attrOn, header)
+	}
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("load csv error line %v: %w", line, err)
+		}
+		on, err := parseTime(record[0])
+		if err != nil {
+			return fmt.Errorf("load csv error line %v: column 'on' must be a valid date in the format %q or an RFC 3339 time got %q", line, timeFormat, record[0])
+		}
+		for i, cell := range record[1:] {
+			if cell == "" {
+				continue
+			}
+			id := header[i+1]
+			v, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				return fmt.Errorf("load csv error line %v: column %q must be a valid number got %q", line, id, cell)
+			}
+			s, ok := dict[id]
+			if !ok {
+				s = new(Support)
+				dict[id] = s
+			}
+			s.Append(on, v)
+		}
+	}
+}
+
+// binaryMagic starts all binary dumps.
+const binaryMagic = "TSB1"
+
+// FormatBinary writes supports in a compact binary format, preserving nanosecond times.
+//
+// The format is little endian: the magic "TSB1", the number of series (uint32), then for each series
+// in alphabetical order its name length (uint16), name, number of points (uint64) and points as Unix
+// nanoseconds (int64) and value (float64).
+func FormatBinary(w io.Writer, dict map[string]*Support) error {
+	ids := slices.Sorted(maps.Keys(dict))
+	for _, id := range ids {
+		if len(id) > math.MaxUint16 {
+			return fmt.Errorf("format binary error: series name of %v bytes exceeds %v", len(id), math.MaxUint16)
+		}
+	}
+	bw := bufio.NewWriter(w)
+	var err error
+	write := func(v any) {
+		if err == nil {
+			err = binary.Write(bw, binary.LittleEndian, v)
+		}
+	}
+	write([]byte(binaryMagic))
+	write(uint32(len(ids)))
+	for _, id := range ids {
+		s := dict[id]
+		write(uint16(len(id)))
+		write([]byte(id))
+		write(uint64(s.Len()))
+		for on, v := range s.Values() {
+			write(on.UnixNano())
+			write(v)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("format binary error: %w", err)
+	}
+	return bw.Flush()
+}
+
+// LoadBinary loads supports from the binary format written by FormatBinary.
+func LoadBinary(dict map[string]*Support, r io.Reader) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(binaryMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != binaryMagic {
+		return errors.New("load binary error: missing magic header")
+	}
+	read := func(v any) error { return binary.Read(br, binary.LittleEndian, v) }
+	var n uint32
+	if err := read(&n); err != nil {
+		return fmt.Errorf("load binary error: %w", err)
+	}
+	for range n {
+		var l uint16
+		if err := read(&l); err != nil {
+			return fmt.Errorf("load binary error: %w", err)
+		}
+		name := make([]byte, l)
+		if _, err := io.ReadFull(br, name); err != nil {
+			return fmt.Errorf("load binary error: %w", err)
+		}
+		id := string(name)
+		var count uint64
+		if err := read(&count); err != nil {
+			return fmt.Errorf("load binary error: series %q: %w", id, err)
+		}
+		s, ok := dict[id]
+		if !ok {
+			s = new(Support)
+			dict[id] = s
+		}
+		for range count {
+			var point struct {
+				On int64
+				V  float64
+			}
+			if err := read(&point); err != nil {
+				return fmt.Errorf("load binary error: series %q: %w", id, err)
+			}
+			s.Append(time.Unix(0, point.On).UTC(), point.V)
+		}
+	}
+	return nil
+}
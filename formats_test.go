@@ -0,0 +1,87 @@
+package timeserie_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// TestFormatCSV checks the CSV layout and the round trip.
+func TestFormatCSV(t *testing.T) {
+	dict := map[string]*timeserie.Support{"a": new(timeserie.Support), "b": new(timeserie.Support)}
+	dict["a"].Append(d0, 1.5)
+	dict["a"].Append(d1, 2)
+	dict["b"].Append(d1, -3)
+
+	var b bytes.Buffer
+	if err := timeserie.FormatCSV(&b, dict); err != nil {
+		t.Fatal(err)
+	}
+	want := "on,a,b\n00-1-1,1.5,\n00-1-2,2,-3\n"
+	if b.String() != want {
+		t.Errorf("FormatCSV() = %q want %q", b.String(), want)
+	}
+
+	x := make(map[string]*timeserie.Support)
+	if err := timeserie.LoadCSV(x, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+	if x["a"].Len() != 2 || x["b"].Len() != 1 {
+		t.Errorf("LoadCSV() lengths = %v, %v want 2, 1", x["a"].Len(), x["b"].Len())
+	}
+	if err := timeserie.LoadCSV(x, strings.NewReader("on,a\n00-1-1,x\n")); err == nil {
+		t.Errorf("LoadCSV(invalid number) error = nil want error")
+	}
+}
+
+// TestFormatBinary checks the round trip, with nanosecond precision.
+func TestFormatBinary(t *testing.T) {
+	on := time.Date(2000, 1, 1, 12, 34, 56, 789, time.UTC)
+	dict := map[string]*timeserie.Support{"a": new(timeserie.Support)}
+	dict["a"].Append(on, 1.5)
+	dict["a"].Append(d2, 2)
+
+	var b bytes.Buffer
+	if err := timeserie.FormatBinary(&b, dict); err != nil {
+		t.Fatal(err)
+	}
+	x := make(map[string]*timeserie.Support)
+	if err := timeserie.LoadBinary(x, &b); err != nil {
+		t.Fatal(err)
+	}
+	if x["a"].Len() != 2 {
+		t.Fatalf("LoadBinary().Len() = %v want 2", x["a"].Len())
+	}
+	if got, v := x["a"].At(0); !got.Equal(on) || v != 1.5 {
+		t.Errorf("LoadBinary().At(0) = %v, %v want %v, 1.5", got, v, on)
+	}
+	if err := timeserie.LoadBinary(x, strings.NewReader("nope")); err == nil {
+		t.Errorf("LoadBinary(invalid) error = nil want error")
+	}
+}
+
+// failingWriter fails all writes.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("failing writer") }
+
+// TestFormatBinary_errors checks that long names and write errors are reported.
+func TestFormatBinary_errors(t *testing.T) {
+	long := map[string]*timeserie.Support{strings.Repeat("a", 1<<16): new(timeserie.Support)}
+	var b bytes.Buffer
+	if err := timeserie.FormatBinary(&b, long); err == nil {
+		t.Errorf("FormatBinary(long name) error = nil want error")
+	}
+	if b.Len() != 0 {
+		t.Errorf("FormatBinary(long name) wrote %v bytes want 0", b.Len())
+	}
+	dict := map[string]*timeserie.Support{"a": new(timeserie.Support)}
+	dict["a"].Append(d0, 1)
+	if err := timeserie.FormatBinary(failingWriter{}, dict); err == nil {
+		t.Errorf("FormatBinary(failing writer) error = nil want error")
+	}
+}
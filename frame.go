@@ -0,0 +1,161 @@
+package timeserie
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// Join is the kind of join used to align functions in a Frame.
+type Join int
+
+const (
+	JoinOuter Join = iota // Frame's times are the union of all function's times.
+	JoinInner             // Frame's times are the times where all functions are defined.
+)
+
+// Frame is a table of named, ordered columns aligned on the same times.
+//
+// Missing values are NaN.
+type Frame struct {
+	names   []string
+	times   []time.Time
+	columns [][]float64
+}
+
+// NewFrame creates a new Frame with a column per function, named after 'names'.
+//
+// Rows are the times of the functions, as returned by Iterate, and values are computed using
+// each function's mode.
+func NewFrame(join Join, names []string, functions ...*Function) (*Frame, error) {
+	if len(names) != len(functions) {
+		return nil, fmt.Errorf("cannot create frame: got %v names for %v functions", len(names), len(functions))
+	}
+	if i := duplicate(names); i >= 0 {
+		return nil, fmt.Errorf("cannot create frame: duplicate column %q", names[i])
+	}
+	fr := &Frame{names: slices.Clone(names), columns: make([][]float64, len(functions))}
+	row := make([]float64, len(functions))
+	for t := range Iterate(functions...) {
+		defined := true
+		for i, f := range functions {
+			row[i] = f.F(t)
+			defined = defined && !math.IsNaN(row[i])
+		}
+		if join == JoinInner && !defined {
+			continue
+		}
+		fr.times = append(fr.times, t)
+		for i, v := range row {
+			fr.columns[i] = append(fr.columns[i], v)
+		}
+	}
+	return fr, nil
+}
+
+// FrameOf creates a new Frame from a value change dump dictionary, columns are in
+// alphabetical order.
+func FrameOf(dict map[string]*Support) *Frame {
+	names := slices.Sorted(maps.Keys(dict))
+	var fs []*Function
+	for _, name := range names {
+		fs = append(fs, New(dict[name], ModeNullset))
+	}
+	fr, _ := NewFrame(JoinOuter, names, fs...) // names are unique
+	return fr
+}
+
+// duplicate returns the index of the first duplicated name, or -1.
+func duplicate(names []string) int {
+	for i, name := range names {
+		if slices.Contains(names[:i], name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Len returns the number of rows in the frame.
+func (fr *Frame) Len() int { return len(fr.times) }
+
+// Names returns the column names in order.
+func (fr *Frame) Names() []string { return slices.Clone(fr.names) }
+
+// Column returns the support of the named column, or nil if there is no such column.
+//
+// Missing values are not part of the support.
+func (fr *Frame) Column(name string) *Support {
+	i := slices.Index(fr.names, name)
+	if i < 0 {
+		return nil
+	}
+	s := new(Support)
+	for j, t := range fr.times {
+		s.Append(t, fr.columns[i][j])
+	}
+	return s
+}
+
+// Select returns a new frame with only the named columns, in that order.
+func (fr *Frame) Select(names ...string) (*Frame, error) {
+	if i := duplicate(names); i >= 0 {
+		return nil, fmt.Errorf("cannot select columns: duplicate column %q", names[i])
+	}
+	res := &Frame{names: slices.Clone(names), times: fr.times}
+	for _, name := range names {
+		i := slices.Index(fr.names, name)
+		if i < 0 {
+			return nil, fmt.Errorf("cannot select columns: unknown column %q", name)
+		}
+		res.columns = append(res.columns, fr.columns[i])
+	}
+	return res, nil
+}
+
+// AsOf returns a new frame with an additional column named 'name'. For each row, the value is the
+// value of the last point in 's' at or before the row time, provided that it is not older than
+// 'tolerance'. A negative 'tolerance' means no limit.
+func (fr *Frame) AsOf(name string, s *Support, tolerance time.Duration) (*Frame, error) {
+	if slices.Contains(fr.names, name) {
+		return nil, fmt.Errorf("cannot join column: duplicate column %q", name)
+	}
+	col := make([]float64, len(fr.times))
+	for j, t := range fr.times {
+		col[j] = math.NaN()
+		if prev := s.Find(t); prev >= 0 && (tolerance < 0 || t.Sub(s.times[prev]) <= tolerance) {
+			col[j] = s.values[prev]
+		}
+	}
+	return &Frame{
+		names:   append(slices.Clone(fr.names), name),
+		times:   fr.times,
+		columns: append(slices.Clone(fr.columns), col),
+	}, nil
+}
+
+// Rows returns an iterator over all rows, in chronological order. Each row has a value per column.
+func (fr *Frame) Rows() iter.Seq2[time.Time, []float64] {
+	return func(yield func(time.Time, []float64) bool) {
+		for j, t := range fr.times {
+			row := make([]float64, len(fr.columns))
+			for i := range fr.columns {
+				row[i] = fr.columns[i][j]
+			}
+			if !yield(t, row) {
+				return
+			}
+		}
+	}
+}
+
+// Dict returns the frame as a value change dump dictionary, one support per column.
+func (fr *Frame) Dict() map[string]*Support {
+	dict := make(map[string]*Support)
+	for _, name := range fr.names {
+		dict[name] = fr.Column(name)
+	}
+	return dict
+}
@@ -0,0 +1,89 @@
+package timeserie_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// TestNewFrame checks outer and inner joins on a simple case.
+func TestNewFrame(t *testing.T) {
+	s1 := new(timeserie.Support)
+	s1.Append(d0, 1.0)
+	s1.Append(d1, 2.0)
+	s2 := new(timeserie.Support)
+	s2.Append(d0, 3.0)
+	s2.Append(d2, 4.0)
+	f1, f2 := timeserie.New(s1, timeserie.ModeNullset), timeserie.New(s2, timeserie.ModeNullset)
+
+	outer, err := timeserie.NewFrame(timeserie.JoinOuter, []string{"a", "b"}, f1, f2)
+	if err != nil {
+		t.Fatalf("NewFrame(outer) error: %v", err)
+	}
+	if outer.Len() != 3 {
+		t.Errorf("NewFrame(outer).Len() = %v want 3", outer.Len())
+	}
+	var rows [][]float64
+	for _, row := range outer.Rows() {
+		rows = append(rows, row)
+	}
+	if rows[1][0] != 2.0 || !math.IsNaN(rows[1][1]) {
+		t.Errorf("NewFrame(outer) row 1 = %v want [2 NaN]", rows[1])
+	}
+
+	inner, err := timeserie.NewFrame(timeserie.JoinInner, []string{"a", "b"}, f1, f2)
+	if err != nil {
+		t.Fatalf("NewFrame(inner) error: %v", err)
+	}
+	if inner.Len() != 1 {
+		t.Errorf("NewFrame(inner).Len() = %v want 1", inner.Len())
+	}
+
+	if _, err := timeserie.NewFrame(timeserie.JoinOuter, []string{"a", "a"}, f1, f2); err == nil {
+		t.Errorf("NewFrame(duplicate names) error = nil want error")
+	}
+}
+
+// TestFrame_AsOf checks the tolerance of as-of joins.
+func TestFrame_AsOf(t *testing.T) {
+	s1 := new(timeserie.Support)
+	s1.Append(d0, 1.0)
+	s1.Append(d1, 2.0)
+	s1.Append(d2, 3.0)
+	fr, _ := timeserie.NewFrame(timeserie.JoinOuter, []string{"a"}, timeserie.New(s1, timeserie.ModeNullset))
+
+	rates := new(timeserie.Support)
+	rates.Append(d0.Add(-time.Hour), 10.0)
+
+	x, err := fr.AsOf("rate", rates, 30*time.Hour)
+	if err != nil {
+		t.Fatalf("AsOf() error: %v", err)
+	}
+	c := timeserie.New(x.Column("rate"), timeserie.ModeNullset)
+	if c.F(d0) != 10 || c.F(d1) != 10 || !math.IsNaN(c.F(d2)) {
+		t.Errorf("AsOf() = [%v %v %v] want [10 10 NaN]", c.F(d0), c.F(d1), c.F(d2))
+	}
+}
+
+// TestFrame_Select checks column selection and ordering.
+func TestFrame_Select(t *testing.T) {
+	dict := map[string]*timeserie.Support{"a": new(timeserie.Support), "b": new(timeserie.Support)}
+	dict["a"].Append(d0, 1.0)
+	dict["b"].Append(d0, 2.0)
+	fr := timeserie.FrameOf(dict)
+
+	x, err := fr.Select("b", "a")
+	if err != nil {
+		t.Fatalf("Select() error: %v", err)
+	}
+	for _, row := range x.Rows() {
+		if row[0] != 2.0 || row[1] != 1.0 {
+			t.Errorf("Select(b, a) row = %v want [2 1]", row)
+		}
+	}
+	if _, err := fr.Select("c"); err == nil {
+		t.Errorf("Select(c) error = nil want error")
+	}
+}
@@ -0,0 +1,211 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/expr"
+)
+
+// Range is a Grafana time range, both bounds included.
+type Range struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// slice returns the points of 's' in the range.
+func (r Range) slice(s *timeserie.Support) *timeserie.Support {
+	return s.Slice(r.From, r.To.Add(time.Nanosecond))
+}
+
+// Target is a Grafana query target.
+type Target struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId,omitempty"`
+	Type   string `json:"type,omitempty"` // "timeserie" (default) or "table".
+	Hide   bool   `json:"hide,omitempty"`
+}
+
+// Query is the body of Grafana query requests.
+type Query struct {
+	Range         Range    `json:"range"`
+	IntervalMs    int64    `json:"intervalMs"`
+	MaxDataPoints int      `json:"maxDataPoints"`
+	Targets       []Target `json:"targets"`
+}
+
+// Timeserie is a Grafana time serie response: datapoints are [value, unix milliseconds] pairs.
+type Timeserie struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// Column is a column of a Grafana table.
+type Column struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+// Table is a Grafana table response.
+type Table struct {
+	Type    string   `json:"type"` // always "table".
+	Columns []Column `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+}
+
+// Annotation is a Grafana annotation response.
+type Annotation struct {
+	Annotation json.RawMessage `json:"annotation"`
+	Time       int64           `json:"time"`
+	Title      string          `json:"title"`
+	Text       string          `json:"text"`
+	Tags       []string        `json:"tags"`
+}
+
+// period returns the calendar period to resample series displayed at 'interval', or "" to keep
+// series as they are.
+func period(interval time.Duration) string {
+	switch {
+	case interval >= 365*timeserie.Day:
+		return "year"
+	case interval >= 90*timeserie.Day:
+		return "quarter"
+	case interval >= 28*timeserie.Day:
+		return "month"
+	case interval >= 7*timeserie.Day:
+		return "week"
+	case interval >= timeserie.Day:
+		return "day"
+	}
+	return ""
+}
+
+// health answers Grafana's datasource test.
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	write(w, http.StatusOK, struct{}{})
+}
+
+// search returns the series names containing the requested target, in alphabetical order.
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Target string `json:"target"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody)).Decode(&req); err != nil {
+		fail(w, status(err), "invalid search request: %v", err)
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := []string{}
+	for _, name := range slices.Sorted(maps.Keys(s.dict)) {
+		if strings.Contains(name, req.Target) {
+			names = append(names, name)
+		}
+	}
+	write(w, http.StatusOK, names)
+}
+
+// query evaluates each target on the requested range.
+func (s *Server) query(w http.ResponseWriter, r *http.Request) {
+	var req Query
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody)).Decode(&req); err != nil {
+		fail(w, status(err), "invalid query request: %v", err)
+		return
+	}
+	resp := []any{}
+	for _, target := range req.Targets {
+		if target.Hide {
+			continue
+		}
+		f, err := s.target(target.Target)
+		if err != nil {
+			fail(w, http.StatusBadRequest, "invalid target %q: %v", target.Target, err)
+			return
+		}
+		sup := &f.Support
+		if p := period(time.Duration(req.IntervalMs) * time.Millisecond); p != "" {
+			sup = sample(sup, timeserie.Periods[p], f.Mode())
+		}
+		sup = req.Range.slice(sup)
+		if req.MaxDataPoints > 2 && sup.Len() > req.MaxDataPoints {
+			sup = sup.LTTB(req.MaxDataPoints)
+		}
+		switch target.Type {
+		case "table":
+			table := Table{Type: "table", Columns: []Column{{"Time", "time"}, {target.Target, "number"}}, Rows: [][]any{}}
+			for on, v := range sup.Values() {
+				table.Rows = append(table.Rows, []any{on.UnixMilli(), v})
+			}
+			resp = append(resp, table)
+		default:
+			serie := Timeserie{Target: target.Target, Datapoints: [][2]float64{}}
+			for on, v := range sup.Values() {
+				serie.Datapoints = append(serie.Datapoints, [2]float64{v, float64(on.UnixMilli())})
+			}
+			resp = append(resp, serie)
+		}
+	}
+	write(w, http.StatusOK, resp)
+}
+
+// target returns the function of a target: a series in step mode, or an expression.
+func (s *Server) target(src string) (*timeserie.Function, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if sup, ok := s.dict[src]; ok {
+		return timeserie.New(sup.Clone(), timeserie.ModeStep), nil
+	}
+	x, err := expr.ParseExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	f, err := x.Eval(s.dict)
+	if err != nil {
+		return nil, err
+	}
+	// the result may share supports of the dictionary.
+	return timeserie.New(f.Clone(), f.Mode()), nil
+}
+
+// annotations returns the points of the series named by the annotation query in the range.
+func (s *Server) annotations(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Range      Range           `json:"range"`
+		Annotation json.RawMessage `json:"annotation"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody)).Decode(&req); err != nil {
+		fail(w, status(err), "invalid annotations request: %v", err)
+		return
+	}
+	var annotation struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(req.Annotation, &annotation); err != nil {
+		fail(w, http.StatusBadRequest, "invalid annotation: %v", err)
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sup, ok := s.dict[annotation.Query]
+	if !ok {
+		fail(w, http.StatusNotFound, "unknown series %q", annotation.Query)
+		return
+	}
+	resp := []Annotation{}
+	for on, v := range req.Range.slice(sup).Values() {
+		resp = append(resp, Annotation{
+			Annotation: req.Annotation,
+			Time:       on.UnixMilli(),
+			Title:      annotation.Query,
+			Text:       strconv.FormatFloat(v, 'g', -1, 64),
+			Tags:       []string{},
+		})
+	}
+	write(w, http.StatusOK, resp)
+}
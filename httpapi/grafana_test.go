@@ -0,0 +1,79 @@
+package httpapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite fixtures responses with the current output, to review before committing")
+
+// fixture is a request shaped like the ones of the Grafana JSON datasource plugin, and the expected
+// response, written by hand or reviewed after -update.
+type fixture struct {
+	Path     string          `json:"path"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+func TestGrafana(t *testing.T) {
+	ts := server(t, "")
+	names, err := filepath.Glob("testdata/grafana/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fx fixture
+		if err := json.Unmarshal(data, &fx); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		status, body := call(t, "POST", ts.URL+fx.Path, string(fx.Request))
+		if status != http.StatusOK {
+			t.Errorf("%s: POST %s = %d %s", name, fx.Path, status, body)
+			continue
+		}
+		if *update {
+			fx.Response = json.RawMessage(body)
+			data, err := json.MarshalIndent(fx, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(name, append(data, '\n'), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		var got, want any
+		if err := json.Unmarshal([]byte(body), &got); err != nil {
+			t.Fatalf("%s: invalid response %s: %v", name, body, err)
+		}
+		if err := json.Unmarshal(fx.Response, &want); err != nil {
+			t.Fatalf("%s: invalid fixture response: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			var b bytes.Buffer
+			json.Indent(&b, []byte(body), "", "  ")
+			t.Errorf("%s: POST %s got\n%s\nwant\n%s", name, fx.Path, b.String(), fx.Response)
+		}
+	}
+}
+
+func TestGrafanaHealth(t *testing.T) {
+	ts := server(t, "")
+	if status, _ := call(t, "GET", ts.URL+"/grafana/", ""); status != http.StatusOK {
+		t.Errorf("GET /grafana/ = %d, want %d", status, http.StatusOK)
+	}
+	status, body := call(t, "POST", ts.URL+"/grafana/query", `{"targets":[{"target":"unknown"}]}`)
+	if want := `{"error":"invalid target \"unknown\": 1:1: unknown series \"unknown\""}`; status != http.StatusBadRequest || body != want {
+		t.Errorf("POST /grafana/query = %d %s, want %s", status, body, want)
+	}
+}
@@ -0,0 +1,342 @@
+// Package httpapi serves timeserie Supports over HTTP as JSON.
+//
+// The endpoints are:
+//
+//	GET  /series            list series with their number of points and date range
+//	GET  /series/{name}     get a series
+//	GET  /eval?expr=...     evaluate an expression of package expr
+//	POST /records           append jsonline records of the value change dump format
+//	GET  /metrics           latest value of each series in the Prometheus text exposition format
+//	POST /api/v1/write      ingest a Prometheus remote-write payload
+//	/grafana/...            Grafana JSON datasource (see below)
+//
+// Series endpoints accept the query parameters 'from' and 'to' (YYYY-MM-DD) to keep points in
+// [from, to), 'period' (day, week, month, quarter or year) to resample series at the start of each
+// period, and 'mode' (nullset, step or linear) for the resampling mode, step by default.
+//
+// The Grafana JSON datasource answers GET /grafana/ for the connection test, and POST
+// /grafana/search, /grafana/query and /grafana/annotations. Query targets are series names or
+// expressions, kept in the requested range with both bounds included, resampled to the calendar
+// period of the panel interval, and downsampled with LTTB to maxDataPoints.
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/expr"
+	"github.com/etnz/timeserie/prometheus"
+)
+
+// Server is an http.Handler serving a dictionary of supports.
+type Server struct {
+	mu    sync.RWMutex
+	dict  map[string]*timeserie.Support
+	store string // file where posted records are appended, or empty.
+	mux   *http.ServeMux
+}
+
+// New returns a Server on 'dict'. Posted records are appended to the 'store' file, unless empty.
+func New(dict map[string]*timeserie.Support, store string) *Server {
+	s := &Server{dict: dict, store: store, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /series", s.list)
+	s.mux.HandleFunc("GET /series/{name}", s.get)
+	s.mux.HandleFunc("GET /eval", s.eval)
+	s.mux.HandleFunc("POST /records", s.post)
+	s.mux.HandleFunc("GET /metrics", s.metrics)
+	s.mux.HandleFunc("POST /api/v1/write", s.remoteWrite)
+	s.mux.HandleFunc("GET /grafana/{$}", s.health)
+	s.mux.HandleFunc("POST /grafana/search", s.search)
+	s.mux.HandleFunc("POST /grafana/query", s.query)
+	s.mux.HandleFunc("POST /grafana/annotations", s.annotations)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) { s.mux.ServeHTTP(w, r) }
+
+// Info describes a series.
+type Info struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// Point is a point of a series.
+type Point struct {
+	On    string  `json:"on"`
+	Value float64 `json:"value"`
+}
+
+// Series is a named list of points.
+type Series struct {
+	Name   string  `json:"name"`
+	Points []Point `json:"points"`
+}
+
+// Error is the body of error responses.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// maxBody is the maximum size of request bodies, in bytes.
+const maxBody = 32 << 20
+
+// status returns the response status for an error reading a request body.
+func status(err error) int {
+	if errors.As(err, new(*http.MaxBytesError)) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// write writes 'v' as JSON with 'status'.
+func write(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// fail writes an error response.
+func fail(w http.ResponseWriter, status int, format string, args ...any) {
+	write(w, status, Error{fmt.Sprintf(format, args...)})
+}
+
+// format formats a time in responses.
+func format(t time.Time) string {
+	if t.Truncate(timeserie.Day).Equal(t) {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	infos := []Info{}
+	for _, name := range slices.Sorted(maps.Keys(s.dict)) {
+		sup := s.dict[name]
+		info := Info{Name: name, Count: sup.Len()}
+		if sup.Len() > 0 {
+			first, _ := sup.At(0)
+			last, _ := sup.At(sup.Len() - 1)
+			info.From, info.To = format(first), format(last)
+		}
+		infos = append(infos, info)
+	}
+	write(w, http.StatusOK, infos)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	s.mu.RLock()
+	sup, ok := s.dict[name]
+	if ok {
+		sup = sup.Clone()
+	}
+	s.mu.RUnlock()
+	if !ok {
+		fail(w, http.StatusNotFound, "unknown series %q", name)
+		return
+	}
+	s.respond(w, r, name, timeserie.New(sup, timeserie.ModeStep))
+}
+
+func (s *Server) eval(w http.ResponseWriter, r *http.Request) {
+	src := r.URL.Query().Get("expr")
+	x, err := expr.ParseExpr(src)
+	if err != nil {
+		fail(w, http.StatusBadRequest, "invalid expression: %v", err)
+		return
+	}
+	s.mu.RLock()
+	f, err := x.Eval(s.dict)
+	if err == nil {
+		// the result may share supports of the dictionary.
+		f = timeserie.New(f.Clone(), f.Mode())
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		fail(w, http.StatusBadRequest, "invalid expression: %v", err)
+		return
+	}
+	s.respond(w, r, src, f)
+}
+
+// respond writes 'f' as a series, after applying the range and resampling parameters.
+func (s *Server) respond(w http.ResponseWriter, r *http.Request, name string, f *timeserie.Function) {
+	q := r.URL.Query()
+	sup, err := transform(&f.Support, q.Get("from"), q.Get("to"), q.Get("period"), q.Get("mode"))
+	if err != nil {
+		fail(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	series := Series{Name: name, Points: []Point{}}
+	for on, v := range sup.Values() {
+		series.Points = append(series.Points, Point{format(on), v})
+	}
+	write(w, http.StatusOK, series)
+}
+
+// modes are the accepted resampling modes.
+var modes = map[string]timeserie.Mode{
+	"nullset": timeserie.ModeNullset,
+	"step":    timeserie.ModeStep,
+	"linear":  timeserie.ModeLinear,
+}
+
+// transform keeps points in [from, to) and resamples them at each 'period' with 'mode'. Empty
+// parameters are ignored.
+func transform(s *timeserie.Support, from, to, period, mode string) (*timeserie.Support, error) {
+	if period != "" {
+		cond, ok := timeserie.Periods[period]
+		if !ok {
+			return nil, fmt.Errorf("invalid period %q: expected day, week, month, quarter or year", period)
+		}
+		m := timeserie.ModeStep
+		if mode != "" {
+			if m, ok = modes[mode]; !ok {
+				return nil, fmt.Errorf("invalid mode %q: expected nullset, step or linear", mode)
+			}
+		}
+		s = sample(s, cond, m)
+	}
+	start, end := time.Time{}, time.Unix(1<<62, 0)
+	var err error
+	if from != "" {
+		if start, err = time.Parse(time.DateOnly, from); err != nil {
+			return nil, fmt.Errorf("invalid 'from' date %q: expected YYYY-MM-DD", from)
+		}
+	}
+	if to != "" {
+		if end, err = time.Parse(time.DateOnly, to); err != nil {
+			return nil, fmt.Errorf("invalid 'to' date %q: expected YYYY-MM-DD", to)
+		}
+	}
+	return s.Slice(start, end), nil
+}
+
+// sample samples 's' with 'mode' on days matching 'cond' from its first to its last point.
+func sample(s *timeserie.Support, cond timeserie.TimeCond, mode timeserie.Mode) *timeserie.Support {
+	if s.Len() == 0 {
+		return s
+	}
+	first, _ := s.At(0)
+	last, _ := s.At(s.Len() - 1)
+	days := timeserie.Days(first, last.Add(timeserie.Day), cond)
+	return &timeserie.Sample(days, timeserie.New(s, mode)).Support
+}
+
+// Posted is the response of POST /records.
+type Posted struct {
+	Points int `json:"points"` // Number of points added.
+}
+
+func (s *Server) post(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		fail(w, status(err), "cannot read body: %v", err)
+		return
+	}
+	records := make(map[string]*timeserie.Support)
+	if err := timeserie.Load(records, bytes.NewReader(body)); err != nil {
+		fail(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store != "" {
+		if err := appendFile(s.store, body); err != nil {
+			fail(w, http.StatusInternalServerError, "cannot persist records: %v", err)
+			return
+		}
+	}
+	var n int
+	for name, sup := range records {
+		dst, ok := s.dict[name]
+		if !ok {
+			dst = new(timeserie.Support)
+			s.dict[name] = dst
+		}
+		if m := sup.Meta(); !m.IsZero() {
+			dst.SetMeta(m)
+		}
+		for on, v := range sup.Values() {
+			dst.Append(on, v)
+			n++
+		}
+	}
+	write(w, http.StatusOK, Posted{n})
+}
+
+func (s *Server) metrics(w http.ResponseWriter, r *http.Request) {
+	var b bytes.Buffer
+	s.mu.RLock()
+	err := prometheus.Expose(&b, s.dict)
+	s.mu.RUnlock()
+	if err != nil {
+		fail(w, http.StatusInternalServerError, "cannot expose metrics: %v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(b.Bytes())
+}
+
+func (s *Server) remoteWrite(w http.ResponseWriter, r *http.Request) {
+	samples := make(map[string]*timeserie.Support)
+	n, err := prometheus.Ingest(samples, http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		fail(w, status(err), "%v", err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store != "" {
+		var b bytes.Buffer
+		if err := timeserie.Format(&b, samples); err != nil {
+			fail(w, http.StatusInternalServerError, "cannot format samples: %v", err)
+			return
+		}
+		if err := appendFile(s.store, b.Bytes()); err != nil {
+			fail(w, http.StatusInternalServerError, "cannot persist samples: %v", err)
+			return
+		}
+	}
+	for name, sup := range samples {
+		dst, ok := s.dict[name]
+		if !ok {
+			dst = new(timeserie.Support)
+			s.dict[name] = dst
+		}
+		for on, v := range sup.Values() {
+			dst.Upsert(on, v)
+		}
+	}
+	write(w, http.StatusOK, Posted{n})
+}
+
+// appendFile appends 'data' to the file 'name', as complete lines.
+func appendFile(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,179 @@
+package httpapi_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/httpapi"
+	"github.com/etnz/timeserie/prometheus"
+)
+
+const dump = `{"on":"24-1-1","income":100,"expenses":40}
+{"on":"24-1-15","expenses":20}
+{"on":"24-2-1","income":110,"expenses":50}
+`
+
+// server returns a test server on the dump, storing posted records in 'store'.
+func server(t *testing.T, store string) *httptest.Server {
+	t.Helper()
+	dict := make(map[string]*timeserie.Support)
+	if err := timeserie.Load(dict, strings.NewReader(dump)); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(httpapi.New(dict, store))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// call sends a request and returns the status and the body.
+func call(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, strings.TrimSpace(string(data))
+}
+
+func TestList(t *testing.T) {
+	ts := server(t, "")
+	status, body := call(t, "GET", ts.URL+"/series", "")
+	want := `[{"name":"expenses","count":3,"from":"2024-01-01","to":"2024-02-01"},{"name":"income","count":2,"from":"2024-01-01","to":"2024-02-01"}]`
+	if status != http.StatusOK || body != want {
+		t.Errorf("GET /series = %d %s, want %s", status, body, want)
+	}
+}
+
+func TestGet(t *testing.T) {
+	ts := server(t, "")
+	tests := []struct {
+		query  string
+		status int
+		want   string
+	}{
+		{"/series/expenses", 200, `{"name":"expenses","points":[{"on":"2024-01-01","value":40},{"on":"2024-01-15","value":20},{"on":"2024-02-01","value":50}]}`},
+		{"/series/expenses?from=2024-01-10&to=2024-02-01", 200, `{"name":"expenses","points":[{"on":"2024-01-15","value":20}]}`},
+		{"/series/expenses?period=week&from=2024-01-15&to=2024-01-30", 200, `{"name":"expenses","points":[{"on":"2024-01-15","value":20},{"on":"2024-01-22","value":20},{"on":"2024-01-29","value":20}]}`},
+		{"/series/expenses?period=week&mode=nullset&from=2024-01-15&to=2024-01-30", 200, `{"name":"expenses","points":[{"on":"2024-01-15","value":20}]}`},
+		{"/series/unknown", 404, `{"error":"unknown series \"unknown\""}`},
+		{"/series/expenses?from=yesterday", 400, `{"error":"invalid 'from' date \"yesterday\": expected YYYY-MM-DD"}`},
+		{"/series/expenses?period=hour", 400, `{"error":"invalid period \"hour\": expected day, week, month, quarter or year"}`},
+	}
+	for _, test := range tests {
+		status, body := call(t, "GET", ts.URL+test.query, "")
+		if status != test.status || body != test.want {
+			t.Errorf("GET %s = %d %s, want %d %s", test.query, status, body, test.status, test.want)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	ts := server(t, "")
+	status, body := call(t, "GET", ts.URL+"/eval?expr=income-expenses&from=2024-02-01", "")
+	want := `{"name":"income-expenses","points":[{"on":"2024-02-01","value":60}]}`
+	if status != http.StatusOK || body != want {
+		t.Errorf("GET /eval = %d %s, want %s", status, body, want)
+	}
+	status, _ = call(t, "GET", ts.URL+"/eval?expr=income-", "")
+	if status != http.StatusBadRequest {
+		t.Errorf("GET /eval with invalid expression = %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestPost(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "store.jsonl")
+	ts := server(t, store)
+	records := `{"on":"24-3-1","income":120,"savings":10}`
+	status, body := call(t, "POST", ts.URL+"/records", records)
+	if want := `{"points":2}`; status != http.StatusOK || body != want {
+		t.Errorf("POST /records = %d %s, want %s", status, body, want)
+	}
+	status, body = call(t, "GET", ts.URL+"/series/savings", "")
+	if want := `{"name":"savings","points":[{"on":"2024-03-01","value":10}]}`; status != http.StatusOK || body != want {
+		t.Errorf("GET /series/savings = %d %s, want %s", status, body, want)
+	}
+	data, err := os.ReadFile(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != records+"\n" {
+		t.Errorf("store content = %q, want %q", got, records+"\n")
+	}
+
+	status, _ = call(t, "POST", ts.URL+"/records", `{"on":"march"}`)
+	if status != http.StatusBadRequest {
+		t.Errorf("POST /records with invalid records = %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestPost_meta(t *testing.T) {
+	dict := make(map[string]*timeserie.Support)
+	ts := httptest.NewServer(httpapi.New(dict, ""))
+	defer ts.Close()
+	records := `{"meta":{"id":"savings","unit":"EUR"}}` + "\n" + `{"on":"24-3-1","savings":10}`
+	if status, body := call(t, "POST", ts.URL+"/records", records); status != http.StatusOK {
+		t.Fatalf("POST /records = %d %s", status, body)
+	}
+	if got := dict["savings"].Meta().Unit; got != "EUR" {
+		t.Errorf("posted unit = %q, want %q", got, "EUR")
+	}
+}
+
+func TestPost_tooLarge(t *testing.T) {
+	ts := server(t, "")
+	records := strings.Repeat(`{"on":"24-3-1","income":120}`+"\n", 2<<20)
+	if status, _ := call(t, "POST", ts.URL+"/records", records); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST /records too large = %d, want %d", status, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestPrometheus(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "store.jsonl")
+	ts := server(t, store)
+	status, body := call(t, "GET", ts.URL+"/metrics", "")
+	want := "# TYPE expenses gauge\nexpenses 50\n# TYPE income gauge\nincome 110"
+	if status != http.StatusOK || body != want {
+		t.Errorf("GET /metrics = %d %q, want %q", status, body, want)
+	}
+
+	expenses, income := new(timeserie.Support), new(timeserie.Support)
+	expenses.Append(timeserie.DayDate(2024, 2, 1).Add(12*time.Hour), 55)
+	income.Append(timeserie.DayDate(2024, 3, 1), 120)
+	payload := prometheus.Marshal(map[string]*timeserie.Support{"expenses": expenses, `income{currency="EUR"}`: income})
+	status, body = call(t, "POST", ts.URL+"/api/v1/write", string(payload))
+	if want := `{"points":2}`; status != http.StatusOK || body != want {
+		t.Errorf("POST /api/v1/write = %d %s, want %s", status, body, want)
+	}
+	status, body = call(t, "GET", ts.URL+"/series/expenses?from=2024-02-01", "")
+	if want := `{"name":"expenses","points":[{"on":"2024-02-01","value":50},{"on":"2024-02-01T12:00:00Z","value":55}]}`; status != http.StatusOK || body != want {
+		t.Errorf("GET /series/expenses = %d %s, want %s", status, body, want)
+	}
+	data, err := os.ReadFile(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{ "on":"2024-02-01T12:00:00Z", "expenses":55}`; !strings.Contains(string(data), want) {
+		t.Errorf("store content = %q, want %q", data, want)
+	}
+	status, body = call(t, "GET", ts.URL+"/metrics", "")
+	want = "# TYPE expenses gauge\nexpenses 55\n# TYPE income gauge\nincome 110\nincome{currency=\"EUR\"} 120"
+	if status != http.StatusOK || body != want {
+		t.Errorf("GET /metrics = %d %q, want %q", status, body, want)
+	}
+}
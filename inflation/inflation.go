@@ -0,0 +1,72 @@
+// Package inflation adjusts series for inflation using price index series, like a monthly consumer
+// price index (CPI).
+//
+// Indexes are step functions: the index at a time is the latest published one.
+package inflation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// Rebase returns 'index' scaled so that its value at 'base' is 'value', like 100.
+func Rebase(index *timeserie.Support, base time.Time, value float64) (*timeserie.Support, error) {
+	b, ok := index.Latest(base)
+	if !ok || b == 0 {
+		return nil, fmt.Errorf("no index at base date %s", base.Format(time.DateOnly))
+	}
+	f := timeserie.Times(timeserie.New(index, timeserie.ModeNullset), timeserie.Constant(value/b))
+	f.SetMeta(index.Meta())
+	return &f.Support, nil
+}
+
+// Deflate returns the 'nominal' values in real terms of the 'base' date: each value is multiplied
+// by index(base)/index(t). Points before the first index are dropped from the result, and their
+// times are returned.
+func Deflate(nominal *timeserie.Function, index *timeserie.Support, base time.Time) (*timeserie.Function, []time.Time, error) {
+	rebased, err := Rebase(index, base, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	var times, missing []time.Time
+	for t := range nominal.Times() {
+		if _, ok := rebased.Latest(t); ok {
+			times = append(times, t)
+		} else {
+			missing = append(missing, t)
+		}
+	}
+	idx := timeserie.Sample(times, timeserie.New(rebased, timeserie.ModeStep))
+	res := timeserie.Div(timeserie.Sample(times, nominal), idx)
+	res = timeserie.New(&res.Support, nominal.Mode())
+	res.SetMeta(nominal.Meta())
+	return res, missing, nil
+}
+
+// Chain links indexes with different bases into a single index in the base of the last one. Each
+// index is used from its first point, where the previous indexes are scaled to match it.
+func Chain(indexes ...*timeserie.Support) (*timeserie.Support, error) {
+	if len(indexes) == 0 {
+		return new(timeserie.Support), nil
+	}
+	res := indexes[0].Clone()
+	for _, next := range indexes[1:] {
+		if next.Len() == 0 {
+			continue
+		}
+		link, v := next.At(0)
+		prev, ok := res.Latest(link)
+		if !ok || prev == 0 {
+			return nil, fmt.Errorf("indexes do not overlap at %s", link.Format(time.DateOnly))
+		}
+		scaled := timeserie.Times(timeserie.New(res.Slice(time.Time{}, link), timeserie.ModeNullset), timeserie.Constant(v/prev))
+		res = &scaled.Support
+		for t, v := range next.Values() {
+			res.Append(t, v)
+		}
+		res.SetMeta(next.Meta())
+	}
+	return res, nil
+}
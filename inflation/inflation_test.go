@@ -0,0 +1,98 @@
+package inflation_test
+
+import (
+	"maps"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/inflation"
+)
+
+// load loads a dump.
+func load(t *testing.T, src string) map[string]*timeserie.Support {
+	t.Helper()
+	d := make(map[string]*timeserie.Support)
+	if err := timeserie.Load(d, strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// values returns the rounded values of 's' by date.
+func values(s *timeserie.Support) map[string]float64 {
+	m := make(map[string]float64)
+	for t, v := range s.Values() {
+		m[t.Format(time.DateOnly)] = math.Round(v*1e4) / 1e4
+	}
+	return m
+}
+
+const cpi = `{"meta":{"id":"cpi","description":"consumer price index"}}
+{"on":"24-1-1","cpi":110}
+{"on":"24-2-1","cpi":121}
+{"on":"24-3-1","cpi":132}
+`
+
+func TestRebase(t *testing.T) {
+	d := load(t, cpi)
+	got, err := inflation.Rebase(d["cpi"], timeserie.DayDate(2024, 2, 15), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"2024-01-01": 90.9091, "2024-02-01": 100, "2024-03-01": 109.0909}
+	if !maps.Equal(values(got), want) {
+		t.Errorf("Rebase() = %v, want %v", values(got), want)
+	}
+	if got.Meta().Description != "consumer price index" {
+		t.Errorf("Rebase() meta = %+v, want the index meta", got.Meta())
+	}
+	if _, err := inflation.Rebase(d["cpi"], timeserie.DayDate(2023, 1, 1), 100); err == nil {
+		t.Error("Rebase() before the first index succeeded, want an error")
+	}
+}
+
+func TestDeflate(t *testing.T) {
+	d := load(t, cpi+`{"on":"23-12-24","rent":1000}
+{"on":"24-1-5","rent":1000}
+{"on":"24-2-5","rent":1100}
+{"on":"24-3-5","rent":1100}
+`)
+	rent := timeserie.New(d["rent"], timeserie.ModeNullset)
+	got, missing, err := inflation.Deflate(rent, d["cpi"], timeserie.DayDate(2024, 1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// In January 2024 money, the rent is 1000 then 1000 and 916.67.
+	want := map[string]float64{"2024-01-05": 1000, "2024-02-05": 1000, "2024-03-05": 916.6667}
+	if !maps.Equal(values(&got.Support), want) {
+		t.Errorf("Deflate() = %v, want %v", values(&got.Support), want)
+	}
+	if len(missing) != 1 || !missing[0].Equal(timeserie.DayDate(2023, 12, 24)) {
+		t.Errorf("Deflate() missing = %v, want [2023-12-24]", missing)
+	}
+	if got.Mode() != timeserie.ModeNullset {
+		t.Errorf("Deflate() mode = %v, want %v", got.Mode(), timeserie.ModeNullset)
+	}
+}
+
+func TestChain(t *testing.T) {
+	d := load(t, `{"on":"20-1-1","old":100}
+{"on":"21-1-1","old":104}
+{"on":"22-1-1","old":110,"new":100}
+{"on":"23-1-1","old":120,"new":108}
+`)
+	got, err := inflation.Chain(d["old"], d["new"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"2020-01-01": 90.9091, "2021-01-01": 94.5455, "2022-01-01": 100, "2023-01-01": 108}
+	if !maps.Equal(values(got), want) {
+		t.Errorf("Chain() = %v, want %v", values(got), want)
+	}
+	if _, err := inflation.Chain(d["new"], d["old"]); err == nil {
+		t.Error("Chain() of indexes without overlap succeeded, want an error")
+	}
+}
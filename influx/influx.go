@@ -0,0 +1,324 @@
+// Package influx reads and writes supports in the InfluxDB line protocol.
+//
+// A line is 'measurement,tag=value,... field=value,... timestamp', where the timestamp is in
+// nanoseconds since the Unix epoch. Each field of a line is a point of the series named by a Scheme
+// after the measurement, the tags and the field, and Format splits the names back with the same
+// Scheme.
+//
+// Float, integer ('12i'), unsigned ('12u') and boolean (1 for true, 0 for false) fields are read;
+// string fields are ignored.
+package influx
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// Tag is a tag of a line.
+type Tag struct{ Key, Value string }
+
+// Field is a numerical field of a line.
+type Field struct {
+	Key   string
+	Value float64
+}
+
+// Point is a line of the line protocol.
+type Point struct {
+	Measurement string
+	Tags        []Tag // Ordered by key.
+	Fields      []Field
+	Time        time.Time // Zero if the line has no timestamp.
+}
+
+// Scheme names the series of a field of a measurement with tags, and splits the names back to write
+// them.
+type Scheme struct {
+	Name  func(measurement string, tags []Tag, field string) string
+	Split func(id string) (measurement string, tags []Tag, field string, err error) // Inverse of Name.
+}
+
+// SchemeField names series after the field: 'field'. Fields of different measurements or tags are
+// merged into the same series. Series are written as fields of 'measurement'.
+func SchemeField(measurement string) Scheme {
+	return Scheme{
+		Name: func(_ string, _ []Tag, field string) string { return field },
+		Split: func(id string) (string, []Tag, string, error) {
+			return measurement, nil, id, nil
+		},
+	}
+}
+
+// SchemeMeasurement names series after the measurement and the field: 'measurement.field'. Fields
+// of different tags are merged into the same series. Names are split at the last dot.
+var SchemeMeasurement = Scheme{
+	Name: func(measurement string, _ []Tag, field string) string { return measurement + "." + field },
+	Split: func(id string) (string, []Tag, string, error) {
+		i := strings.LastIndexByte(id, '.')
+		if i <= 0 || i == len(id)-1 {
+			return "", nil, "", fmt.Errorf("series %q is not named 'measurement.field'", id)
+		}
+		return id[:i], nil, id[i+1:], nil
+	},
+}
+
+// SchemeTags names series after the measurement, the tag values and the field:
+// 'measurement.value1.value2.field', with tag values ordered by tag key. Tag keys are not in the
+// names, so they cannot be written back.
+var SchemeTags = Scheme{
+	Name: func(measurement string, tags []Tag, field string) string {
+		parts := []string{measurement}
+		for _, t := range tags {
+			parts = append(parts, t.Value)
+		}
+		return strings.Join(append(parts, field), ".")
+	},
+	Split: func(id string) (string, []Tag, string, error) {
+		return "", nil, "", fmt.Errorf("series %q cannot be written: tag keys are not kept by SchemeTags", id)
+	},
+}
+
+// SchemeSeries names series after the line protocol series key and the field, escaped as in a line:
+// 'measurement,key1=value1,key2=value2 field', with tags ordered by key. Fields of different
+// measurements or tags are always different series.
+var SchemeSeries = Scheme{
+	Name: func(measurement string, tags []Tag, field string) string {
+		var b strings.Builder
+		b.WriteString(escape(measurement, ", "))
+		for _, t := range tags {
+			b.WriteString("," + escape(t.Key, ",= ") + "=" + escape(t.Value, ",= "))
+		}
+		b.WriteString(" " + escape(field, ",= "))
+		return b.String()
+	},
+	Split: func(id string) (string, []Tag, string, error) {
+		p, err := Parse(id + "=0")
+		if err != nil || len(p.Fields) != 1 || !p.Time.IsZero() {
+			return "", nil, "", fmt.Errorf("series %q is not named 'measurement,tags field'", id)
+		}
+		return p.Measurement, p.Tags, p.Fields[0].Key, nil
+	},
+}
+
+// Load reads line protocol from 'r' into 'dict', naming series with 'scheme', one line at a time.
+// Points without timestamp are loaded at 'now'.
+func Load(dict map[string]*timeserie.Support, r io.Reader, scheme Scheme, now time.Time) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		p, err := Parse(text)
+		if err != nil {
+			return fmt.Errorf("line protocol error line %v %q: %w", line, text, err)
+		}
+		on := p.Time
+		if on.IsZero() {
+			on = now
+		}
+		for _, f := range p.Fields {
+			id := scheme.Name(p.Measurement, p.Tags, f.Key)
+			s, ok := dict[id]
+			if !ok {
+				s = new(timeserie.Support)
+				dict[id] = s
+			}
+			s.Append(on, f.Value)
+		}
+	}
+	return scanner.Err()
+}
+
+// Parse parses a line of the line protocol.
+func Parse(line string) (p Point, err error) {
+	p.Measurement, line = next(line, ", ")
+	if p.Measurement == "" {
+		return p, errors.New("missing measurement")
+	}
+	for strings.HasPrefix(line, ",") {
+		var t Tag
+		if t.Key, line = next(line[1:], "= ,"); t.Key == "" || !strings.HasPrefix(line, "=") {
+			return p, errors.New("invalid tag: expected key=value")
+		}
+		if t.Value, line = next(line[1:], ", "); t.Value == "" {
+			return p, fmt.Errorf("tag %q has no value", t.Key)
+		}
+		p.Tags = append(p.Tags, t)
+	}
+	slices.SortStableFunc(p.Tags, func(a, b Tag) int { return cmp.Compare(a.Key, b.Key) })
+	if line = strings.TrimLeft(line, " "); line == "" {
+		return p, errors.New("missing fields")
+	}
+	for {
+		var key, value string
+		if key, line = next(line, "= ,"); key == "" || !strings.HasPrefix(line, "=") {
+			return p, errors.New("invalid field: expected key=value")
+		}
+		line = line[1:]
+		if strings.HasPrefix(line, `"`) {
+			// String fields are ignored.
+			if line, err = skipString(line); err != nil {
+				return p, fmt.Errorf("field %q: %w", key, err)
+			}
+		} else {
+			value, line = next(line, ", ")
+			v, err := parseValue(value)
+			if err != nil {
+				return p, fmt.Errorf("field %q: %w", key, err)
+			}
+			p.Fields = append(p.Fields, Field{key, v})
+		}
+		if !strings.HasPrefix(line, ",") {
+			break
+		}
+		line = line[1:]
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		ns, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid timestamp %q: expected nanoseconds", line)
+		}
+		p.Time = time.Unix(0, ns).UTC()
+	}
+	return p, nil
+}
+
+// next returns the unescaped token of 's' up to the first unescaped byte in 'stops', and the rest
+// of 's' from that byte.
+func next(s, stops string) (string, string) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(`,= \"`, s[i+1]) >= 0:
+			i++
+			b.WriteByte(s[i])
+		case strings.IndexByte(stops, c) >= 0:
+			return b.String(), s[i:]
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), ""
+}
+
+// skipString skips the quoted string at the start of 's' and returns the rest.
+func skipString(s string) (string, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return s[i+1:], nil
+		}
+	}
+	return "", errors.New("unterminated string")
+}
+
+// parseValue parses a numerical or boolean field value.
+func parseValue(v string) (float64, error) {
+	switch v {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, nil
+	}
+	switch {
+	case strings.HasSuffix(v, "i"):
+		i, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", v)
+		}
+		return float64(i), nil
+	case strings.HasSuffix(v, "u"):
+		u, err := strconv.ParseUint(v[:len(v)-1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid unsigned integer %q", v)
+		}
+		return float64(u), nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("invalid value %q", v)
+	}
+	return f, nil
+}
+
+// Format writes supports as lines, one line per series key and time, with a field per series, the
+// series being split into measurement, tags and field by 'scheme'. Lines are in chronological order,
+// then ordered by series key, and fields are in alphabetical order of the series.
+func Format(w io.Writer, dict map[string]*timeserie.Support, scheme Scheme) error {
+	ids := slices.Sorted(maps.Keys(dict))
+	type field struct {
+		key string
+		f   *timeserie.Function
+	}
+	lines := make(map[string][]field) // fields by escaped series key.
+	var fs []*timeserie.Function
+	for _, id := range ids {
+		m, tags, key, err := scheme.Split(id)
+		if err != nil {
+			return fmt.Errorf("format line protocol error: %w", err)
+		}
+		series := escape(m, ", ")
+		for _, t := range tags {
+			series += "," + escape(t.Key, ",= ") + "=" + escape(t.Value, ",= ")
+		}
+		f := timeserie.New(dict[id], timeserie.ModeNullset)
+		lines[series] = append(lines[series], field{escape(key, ",= "), f})
+		fs = append(fs, f)
+	}
+	keys := slices.Sorted(maps.Keys(lines))
+	bw := bufio.NewWriter(w)
+	for t := range timeserie.Iterate(fs...) {
+		for _, series := range keys {
+			sep := byte(' ')
+			for _, f := range lines[series] {
+				v := f.f.F(t)
+				if math.IsNaN(v) {
+					continue
+				}
+				if sep == ' ' {
+					bw.WriteString(series)
+				}
+				bw.WriteByte(sep)
+				bw.WriteString(f.key)
+				bw.WriteByte('=')
+				bw.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+				sep = ','
+			}
+			if sep == ',' {
+				fmt.Fprintf(bw, " %d\n", t.UnixNano())
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// escape escapes the bytes in 'chars', and backslashes that would otherwise escape the next byte.
+func escape(s, chars string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if strings.IndexByte(chars, c) >= 0 || c == '\\' && (i+1 == len(s) || strings.IndexByte(`,= \"`, s[i+1]) >= 0) {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
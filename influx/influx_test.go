@@ -0,0 +1,163 @@
+package influx_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/influx"
+)
+
+func TestParse(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 12, 30, 0, 123456789, time.UTC)
+	tests := []struct {
+		line string
+		want influx.Point
+	}{
+		{`cpu usage=0.5 1704112200123456789`, influx.Point{Measurement: "cpu", Fields: []influx.Field{{"usage", 0.5}}, Time: ts}},
+		{`cpu,region=eu,host=a usage=1e3,count=12i,total=7u,up=t,off=FALSE 1704112200123456789`,
+			influx.Point{Measurement: "cpu", Tags: []influx.Tag{{"host", "a"}, {"region", "eu"}},
+				Fields: []influx.Field{{"usage", 1000}, {"count", 12}, {"total", 7}, {"up", 1}, {"off", 0}}, Time: ts}},
+		{`my\ room,sensor\,id=x\=1 temp\ c=21.5,note="a \"quoted\", text" 1704112200123456789`,
+			influx.Point{Measurement: "my room", Tags: []influx.Tag{{"sensor,id", "x=1"}}, Fields: []influx.Field{{"temp c", 21.5}}, Time: ts}},
+		{`power value=3`, influx.Point{Measurement: "power", Fields: []influx.Field{{"value", 3}}}},
+	}
+	for _, test := range tests {
+		got, err := influx.Parse(test.line)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", test.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", test.line, got, test.want)
+		}
+	}
+
+	for _, line := range []string{
+		`,host=a value=1`,
+		`cpu,host value=1`,
+		`cpu`,
+		`cpu value=abc`,
+		`cpu value=1 yesterday`,
+		`cpu note="open`,
+	} {
+		if _, err := influx.Parse(line); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", line)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	src := `# energy readings
+meter,site=home,phase=1 kwh=1.5,status="ok" 1704067200000000000
+meter,site=home,phase=2 kwh=2.5 1704067200000000000
+
+meter,site=home,phase=1 kwh=3 1704153600000000000
+meter,site=home,phase=1 kwh=4
+`
+	now := timeserie.DayDate(2024, 1, 3)
+	dict := make(map[string]*timeserie.Support)
+	if err := influx.Load(dict, strings.NewReader(src), influx.SchemeTags, now); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	timeserie.Format(&b, dict)
+	want := `{ "on":"24-1-1", "meter.1.home.kwh":1.5, "meter.2.home.kwh":2.5}
+{ "on":"24-1-2", "meter.1.home.kwh":3}
+{ "on":"24-1-3", "meter.1.home.kwh":4}
+`
+	if got := b.String(); got != want {
+		t.Errorf("Load(SchemeTags) =\n%s\nwant\n%s", got, want)
+	}
+
+	dict = make(map[string]*timeserie.Support)
+	if err := influx.Load(dict, strings.NewReader(src), influx.SchemeMeasurement, now); err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := dict["meter.kwh"]; len(dict) != 1 || !ok || s.Len() != 4 {
+		t.Errorf("Load(SchemeMeasurement) = %v, want 4 points in 'meter.kwh'", dict)
+	}
+
+	err := influx.Load(make(map[string]*timeserie.Support), strings.NewReader("cpu value=1\ncpu value=x\n"), influx.SchemeField(""), now)
+	if want := `line protocol error line 2 "cpu value=x": field "value": invalid value "x"`; err == nil || err.Error() != want {
+		t.Errorf("Load() error = %v, want %s", err, want)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	a, b := new(timeserie.Support), new(timeserie.Support)
+	d0 := time.Date(2024, 1, 1, 0, 0, 0, 1, time.UTC)
+	a.Append(d0, 1.5)
+	a.Append(d0.Add(time.Hour), 2)
+	b.Append(d0, 1e21)
+	dict := map[string]*timeserie.Support{"kwh": a, `room temp,=\`: b}
+	var w strings.Builder
+	if err := influx.Format(&w, dict, influx.SchemeField("my home")); err != nil {
+		t.Fatal(err)
+	}
+	want := `my\ home kwh=1.5,room\ temp\,\=\\=1e+21 1704067200000000001
+my\ home kwh=2 1704070800000000001
+`
+	if got := w.String(); got != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", got, want)
+	}
+
+	got := make(map[string]*timeserie.Support)
+	if err := influx.Load(got, strings.NewReader(w.String()), influx.SchemeField(""), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, dict) {
+		t.Errorf("Load(Format()) = %v, want %v", got, dict)
+	}
+
+	if err := influx.Format(new(strings.Builder), dict, influx.SchemeTags); err == nil {
+		t.Errorf("Format(SchemeTags) want error")
+	}
+}
+
+// TestFormat_schemes checks that lines read with a scheme are written back with the same scheme.
+func TestFormat_schemes(t *testing.T) {
+	src := `meter,phase=1,site=home kwh=1.5,volt=230 1704067200000000000
+meter,phase=2,site=home kwh=2.5 1704067200000000000
+my\ meter,phase=1,site=a\,b kwh=3 1704067200000000000
+meter,phase=1,site=home kwh=4 1704153600000000000
+`
+	for name, test := range map[string]struct {
+		scheme influx.Scheme
+		src    string
+	}{
+		"series":      {influx.SchemeSeries, src},
+		"measurement": {influx.SchemeMeasurement, "meter kwh=1.5,volt=230 1704067200000000000\nmy.meter kwh=3 1704067200000000000\n"},
+	} {
+		scheme := test.scheme
+		dict := make(map[string]*timeserie.Support)
+		if err := influx.Load(dict, strings.NewReader(test.src), scheme, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		var w strings.Builder
+		if err := influx.Format(&w, dict, scheme); err != nil {
+			t.Fatalf("Format(%s) error: %v", name, err)
+		}
+		got := make(map[string]*timeserie.Support)
+		if err := influx.Load(got, strings.NewReader(w.String()), scheme, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, dict) {
+			t.Errorf("Load(Format(%s)) = %v, want %v", name, got, dict)
+		}
+	}
+
+	dict := make(map[string]*timeserie.Support)
+	if err := influx.Load(dict, strings.NewReader(src), influx.SchemeSeries, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	var w strings.Builder
+	if err := influx.Format(&w, dict, influx.SchemeSeries); err != nil {
+		t.Fatal(err)
+	}
+	if w.String() != src {
+		t.Errorf("Format(SchemeSeries) =\n%s\nwant\n%s", w.String(), src)
+	}
+}
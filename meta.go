@@ -0,0 +1,31 @@
+package timeserie
+
+import "maps"
+
+// Meta describes a series.
+type Meta struct {
+	Name        string            `json:"name,omitempty"`        // Human readable name.
+	Unit        string            `json:"unit,omitempty"`        // Unit of the values, like "EUR" or "kWh".
+	Description string            `json:"description,omitempty"` // Free text description.
+	Tags        map[string]string `json:"tags,omitempty"`        // Key/value tags.
+}
+
+// IsZero reports whether 'm' holds no metadata.
+func (m Meta) IsZero() bool {
+	return m.Name == "" && m.Unit == "" && m.Description == "" && len(m.Tags) == 0
+}
+
+// Clone returns a copy of 'm'.
+func (m Meta) Clone() Meta {
+	m.Tags = maps.Clone(m.Tags)
+	return m
+}
+
+// Meta returns the metadata of the support.
+//
+// Metadata is kept by Clone, Slice, Unique and downsampling, but not by operations computing new
+// values.
+func (s *Support) Meta() Meta { return s.meta }
+
+// SetMeta sets the metadata of the support.
+func (s *Support) SetMeta(m Meta) { s.meta = m }
@@ -0,0 +1,68 @@
+package timeserie_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/etnz/timeserie"
+)
+
+// TestMeta checks that metadata headers are loaded, kept by Slice, and formatted back.
+func TestMeta(t *testing.T) {
+	src := `{"meta":{"id":"food","name":"Food","unit":"EUR","tags":{"account":"joint"}}}
+{"meta":{"id":"empty","description":"no points yet"}}
+{"on":"24-1-1","food":12,"rent":800}
+{"on":"24-1-2","food":30}
+`
+	dict := make(map[string]*timeserie.Support)
+	if err := timeserie.Load(dict, strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	want := timeserie.Meta{Name: "Food", Unit: "EUR", Tags: map[string]string{"account": "joint"}}
+	if got := dict["food"].Meta(); !reflect.DeepEqual(got, want) {
+		t.Errorf("food Meta() = %+v, want %+v", got, want)
+	}
+	if got := dict["food"].Slice(d0, timeserie.DayDate(2024, 1, 2)).Meta(); !reflect.DeepEqual(got, want) {
+		t.Errorf("food Slice().Meta() = %+v, want %+v", got, want)
+	}
+	if got := dict["rent"].Meta(); !got.IsZero() {
+		t.Errorf("rent Meta() = %+v, want none", got)
+	}
+	if got := dict["empty"]; got.Len() != 0 || got.Meta().Description != "no points yet" {
+		t.Errorf("empty = %d points and %+v", got.Len(), got.Meta())
+	}
+
+	var b strings.Builder
+	if err := timeserie.Format(&b, dict); err != nil {
+		t.Fatal(err)
+	}
+	wantDump := `{"meta":{"id":"empty","description":"no points yet"}}
+{"meta":{"id":"food","name":"Food","unit":"EUR","tags":{"account":"joint"}}}
+{ "on":"24-1-1", "food":12, "rent":800}
+{ "on":"24-1-2", "food":30}
+`
+	if got := b.String(); got != wantDump {
+		t.Errorf("Format() =\n%s\nwant\n%s", got, wantDump)
+	}
+}
+
+// TestMeta_invalid checks errors on invalid headers, and that a series named "meta" is still data.
+func TestMeta_invalid(t *testing.T) {
+	for _, src := range []string{
+		`{"meta":{"unit":"EUR"}}`,
+		`{"meta":{"id":"food","unit":3}}`,
+		`{"meta":"food"}`,
+	} {
+		if err := timeserie.Load(make(map[string]*timeserie.Support), strings.NewReader(src)); err == nil {
+			t.Errorf("Load(%s) succeeded, want an error", src)
+		}
+	}
+	dict := make(map[string]*timeserie.Support)
+	if err := timeserie.Load(dict, strings.NewReader(`{"on":"24-1-1","meta":3}`)); err != nil {
+		t.Fatal(err)
+	}
+	if dict["meta"].Len() != 1 {
+		t.Errorf("Load() = %v, want a 'meta' series", dict)
+	}
+}
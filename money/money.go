@@ -0,0 +1,183 @@
+// Package money converts amounts between currencies using exchange rate series.
+//
+// Exchange rates are step functions: the rate at a time is the latest known one. A rate series of a
+// currency against a base currency holds the price of one unit of the base in that currency, like
+// "EURUSD" for the price of one euro in US dollars.
+package money
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// Convert returns 'amounts' multiplied by the rate valid at each point time, 'rates' being read as
+// a step function whatever its mode. Points before the first rate are dropped from the result, and
+// their times are returned.
+func Convert(amounts, rates *timeserie.Function) (*timeserie.Function, []time.Time) {
+	s := new(timeserie.Support)
+	var missing []time.Time
+	for t, v := range amounts.Values() {
+		rate, ok := rates.Latest(t)
+		if !ok {
+			missing = append(missing, t)
+			continue
+		}
+		s.Append(t, v*rate)
+	}
+	return timeserie.New(s, amounts.Mode()), missing
+}
+
+// Rates holds exchange rates against a base currency.
+type Rates struct {
+	base   string
+	quotes map[string]*timeserie.Function // price of one base unit by currency.
+}
+
+// NewRates returns empty rates against 'base'.
+func NewRates(base string) *Rates {
+	return &Rates{base: base, quotes: make(map[string]*timeserie.Function)}
+}
+
+// RatesOf returns the rates against 'base' found in 'dict': series named after the base and a
+// currency, like "EURUSD" (USD per EUR), or inverted, like "USDEUR" (EUR per USD). Rates that are not
+// positive and finite, like a zero rate, are skipped.
+func RatesOf(base string, dict map[string]*timeserie.Support) *Rates {
+	r := NewRates(base)
+	for name, s := range dict {
+		if len(name) != 6 {
+			continue
+		}
+		switch {
+		case name[:3] == base && currency(name[3:]):
+			r.Set(name[3:], s)
+		case name[3:] == base && currency(name[:3]):
+			if _, ok := r.quotes[name[:3]]; ok {
+				continue // direct quotes take precedence.
+			}
+			r.quotes[name[:3]] = timeserie.New(valid(s, true), timeserie.ModeStep)
+		}
+	}
+	return r
+}
+
+// currency reports whether 'code' looks like a currency code.
+func currency(code string) bool {
+	return len(code) == 3 && !slices.ContainsFunc([]byte(code), func(c byte) bool { return c < 'A' || c > 'Z' })
+}
+
+// Base returns the base currency.
+func (r *Rates) Base() string { return r.base }
+
+// Set sets the price of one unit of the base currency in 'currency'. Rates that are not positive and
+// finite are skipped.
+func (r *Rates) Set(currency string, s *timeserie.Support) {
+	r.quotes[currency] = timeserie.New(valid(s, false), timeserie.ModeStep)
+}
+
+// valid returns the positive and finite rates of 's', inverted if 'invert'.
+func valid(s *timeserie.Support, invert bool) *timeserie.Support {
+	res := new(timeserie.Support)
+	for t, v := range s.Values() {
+		if invert {
+			v = 1 / v
+		}
+		if v > 0 && !math.IsInf(v, 1) {
+			res.Append(t, v)
+		}
+	}
+	return res
+}
+
+// Currencies returns the currencies with rates, including the base, in alphabetical order.
+func (r *Rates) Currencies() []string {
+	list := []string{r.base}
+	for c := range r.quotes {
+		list = append(list, c)
+	}
+	slices.Sort(list)
+	return slices.Compact(list)
+}
+
+// Rate returns the price of one unit of 'from' in 'to', as a step function. Rates between
+// currencies other than the base are triangulated through the base, and defined from the time both
+// rates are.
+func (r *Rates) Rate(from, to string) (*timeserie.Function, error) {
+	if from == to {
+		return timeserie.Constant(1), nil
+	}
+	qf, err := r.quote(from)
+	if err != nil {
+		return nil, err
+	}
+	qt, err := r.quote(to)
+	if err != nil {
+		return nil, err
+	}
+	if from == r.base {
+		return qt, nil
+	}
+	// The price of 'from' in 'to' is qt/qf.
+	s := new(timeserie.Support)
+	for t := range timeserie.Iterate(qf, qt) {
+		f, okf := qf.Latest(t)
+		q, okt := qt.Latest(t)
+		if okf && okt {
+			s.Append(t, q/f)
+		}
+	}
+	return timeserie.New(s, timeserie.ModeStep), nil
+}
+
+// quote returns the price of one unit of the base in 'currency'.
+func (r *Rates) quote(currency string) (*timeserie.Function, error) {
+	if currency == r.base {
+		return timeserie.Constant(1), nil
+	}
+	if q, ok := r.quotes[currency]; ok {
+		return q, nil
+	}
+	return nil, fmt.Errorf("no exchange rate between %s and %s", r.base, currency)
+}
+
+// Money is a series of amounts in a currency.
+type Money struct {
+	Currency string
+	Amounts  *timeserie.Function
+}
+
+// In returns the amounts converted into 'currency' at the rate valid at each point. Points without
+// rate are dropped from the result, and their times are returned.
+func (m Money) In(currency string, r *Rates) (Money, []time.Time, error) {
+	rate, err := r.Rate(m.Currency, currency)
+	if err != nil {
+		return Money{}, nil, err
+	}
+	f, missing := Convert(m.Amounts, rate)
+	meta := m.Amounts.Meta().Clone()
+	meta.Unit = currency
+	f.SetMeta(meta)
+	return Money{currency, f}, missing, nil
+}
+
+// Sum returns the sum of amounts converted into 'currency', like timeserie.Add. Points without rate
+// are dropped from the sum, and their times are returned in chronological order.
+func Sum(currency string, r *Rates, ms ...Money) (Money, []time.Time, error) {
+	var fs []*timeserie.Function
+	var missing []time.Time
+	for _, m := range ms {
+		c, miss, err := m.In(currency, r)
+		if err != nil {
+			return Money{}, nil, err
+		}
+		fs = append(fs, c.Amounts)
+		missing = append(missing, miss...)
+	}
+	slices.SortFunc(missing, time.Time.Compare)
+	f := timeserie.Add(fs...)
+	f.SetMeta(timeserie.Meta{Unit: currency})
+	return Money{currency, f}, slices.Compact(missing), nil
+}
@@ -0,0 +1,142 @@
+package money_test
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/money"
+)
+
+const rates = `{"on":"24-1-1","EURUSD":1.1,"CHFEUR":1.05}
+{"on":"24-1-10","EURUSD":1.2}
+{"on":"24-1-20","CHFEUR":1}
+`
+
+// dict loads a dump.
+func dict(t *testing.T, src string) map[string]*timeserie.Support {
+	t.Helper()
+	d := make(map[string]*timeserie.Support)
+	if err := timeserie.Load(d, strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// points returns the values of 'f' by date.
+func points(f *timeserie.Function) map[string]float64 {
+	m := make(map[string]float64)
+	for t, v := range f.Values() {
+		m[t.Format(time.DateOnly)] = math.Round(v*1e6) / 1e6
+	}
+	return m
+}
+
+func TestConvert(t *testing.T) {
+	d := dict(t, `{"on":"23-12-31","amount":1}
+{"on":"24-1-1","amount":10, "rate":2}
+{"on":"24-1-5","amount":20}
+{"on":"24-1-15","amount":30, "rate":3}
+`)
+	amounts := timeserie.New(d["amount"], timeserie.ModeNullset)
+	got, missing := money.Convert(amounts, timeserie.New(d["rate"], timeserie.ModeNullset))
+	want := map[string]float64{"2024-01-01": 20, "2024-01-05": 40, "2024-01-15": 90}
+	if p := points(got); !maps.Equal(p, want) {
+		t.Errorf("Convert() = %v, want %v", p, want)
+	}
+	if len(missing) != 1 || !missing[0].Equal(timeserie.DayDate(2023, 12, 31)) {
+		t.Errorf("Convert() missing = %v, want [2023-12-31]", missing)
+	}
+	if got.Mode() != timeserie.ModeNullset {
+		t.Errorf("Convert() mode = %v, want the amounts mode", got.Mode())
+	}
+}
+
+func TestRates(t *testing.T) {
+	r := money.RatesOf("EUR", dict(t, rates))
+	if got, want := r.Currencies(), []string{"CHF", "EUR", "USD"}; !slices.Equal(got, want) {
+		t.Errorf("Currencies() = %v, want %v", got, want)
+	}
+	for _, test := range []struct {
+		from, to string
+		want     map[string]float64
+	}{
+		{"EUR", "USD", map[string]float64{"2024-01-01": 1.1, "2024-01-10": 1.2}},
+		{"USD", "EUR", map[string]float64{"2024-01-01": 0.909091, "2024-01-10": 0.833333}},
+		// CHF to USD through EUR: CHFEUR * EURUSD.
+		{"CHF", "USD", map[string]float64{"2024-01-01": 1.155, "2024-01-10": 1.26, "2024-01-20": 1.2}},
+	} {
+		f, err := r.Rate(test.from, test.to)
+		if err != nil {
+			t.Errorf("Rate(%s, %s) error: %v", test.from, test.to, err)
+			continue
+		}
+		if p := points(f); !maps.Equal(p, test.want) {
+			t.Errorf("Rate(%s, %s) = %v, want %v", test.from, test.to, p, test.want)
+		}
+	}
+	if _, err := r.Rate("EUR", "JPY"); err == nil || err.Error() != "no exchange rate between EUR and JPY" {
+		t.Errorf("Rate(EUR, JPY) error = %v", err)
+	}
+}
+
+// TestRatesOf_zero checks that zero rates are skipped instead of converting to infinite amounts.
+func TestRatesOf_zero(t *testing.T) {
+	r := money.RatesOf("EUR", dict(t, `{"on":"24-1-1","USDEUR":0.9,"EURCHF":0}
+{"on":"24-1-10","USDEUR":0,"EURCHF":0.95}
+`))
+	for _, test := range []struct {
+		from, to string
+		want     map[string]float64
+	}{
+		{"EUR", "USD", map[string]float64{"2024-01-01": 1.111111}},
+		{"EUR", "CHF", map[string]float64{"2024-01-10": 0.95}},
+	} {
+		f, err := r.Rate(test.from, test.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := points(f); !maps.Equal(p, test.want) {
+			t.Errorf("Rate(%s, %s) = %v, want %v", test.from, test.to, p, test.want)
+		}
+	}
+}
+
+func TestMoney(t *testing.T) {
+	r := money.RatesOf("EUR", dict(t, rates))
+	d := dict(t, `{"on":"23-12-20","usd":5}
+{"on":"24-1-5","usd":100,"chf":100}
+{"on":"24-1-15","usd":60,"chf":10}
+`)
+	usd := money.Money{Currency: "USD", Amounts: timeserie.New(d["usd"], timeserie.ModeNullset)}
+	chf := money.Money{Currency: "CHF", Amounts: timeserie.New(d["chf"], timeserie.ModeNullset)}
+
+	eur, missing, err := usd.In("EUR", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]float64{"2024-01-05": 90.909091, "2024-01-15": 50}; !maps.Equal(points(eur.Amounts), want) {
+		t.Errorf("In(EUR) = %v, want %v", points(eur.Amounts), want)
+	}
+	if eur.Currency != "EUR" || eur.Amounts.Meta().Unit != "EUR" || len(missing) != 1 {
+		t.Errorf("In(EUR) = %s (unit %q) with missing %v", eur.Currency, eur.Amounts.Meta().Unit, missing)
+	}
+
+	total, missing, err := money.Sum("EUR", r, usd, chf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]float64{"2024-01-05": 195.909091, "2024-01-15": 60.5}; !maps.Equal(points(total.Amounts), want) {
+		t.Errorf("Sum() = %v, want %v", points(total.Amounts), want)
+	}
+	if len(missing) != 1 || !missing[0].Equal(timeserie.DayDate(2023, 12, 20)) {
+		t.Errorf("Sum() missing = %v, want [2023-12-20]", missing)
+	}
+	if _, _, err := money.Sum("JPY", r, usd); err == nil {
+		t.Error("Sum(JPY) succeeded, want an error")
+	}
+}
@@ -12,8 +12,8 @@ type Mode int
 const (
 	ModeNullset Mode = iota // function defined only on the support, NaN everywhere else.
 	ModeStep                // Function's value between two support events is the value of the earliest.
-	//ModeLinear              // Function's value between two support events is a linear interpolation between the two.
-	LenMode // not a mode but the length of modes
+	ModeLinear              // Function's value between two support events is a linear interpolation between the two, NaN outside.
+	LenMode                 // not a mode but the length of modes
 )
 
 // Function is the interface of all support-based functions.
@@ -25,6 +25,16 @@ type Function struct {
 // New creates a new function defined by its support and the interpolation mode.
 func New(s *Support, mode Mode) *Function { return &Function{Support: *s, mode: mode} }
 
+// Constant returns a step function worth 'v' at all times.
+func Constant(v float64) *Function {
+	s := new(Support)
+	s.Append(time.Time{}, v)
+	return New(s, ModeStep)
+}
+
+// Mode returns the interpolation mode of the function.
+func (f *Function) Mode() Mode { return f.mode }
+
 // F returns the function value at a given time. If not defined on that time, it returns NaN.
 func (f *Function) F(t time.Time) float64 {
 	switch f.mode {
@@ -46,6 +56,12 @@ func (f *Function) F(t time.Time) float64 {
 		} else {
 			return f.values[prev]
 		}
+	case ModeLinear:
+		prev := f.Find(t)
+		if prev >= 0 && f.times[prev].Equal(t) {
+			return f.values[prev]
+		}
+		return FillLinear(&f.Support, t)
 	}
 	return math.NaN()
 }
@@ -79,11 +95,12 @@ func Iterate(functions ...*Function) iter.Seq[time.Time] {
 				}
 			}
 			// now extract the ones that are equals to the min
-			for i, index := range indexes {
-				if index >= functions[i].Len() {
-					continue
-				}
-				if on, _ := functions[i].At(index); on.Equal(m) {
+			// Duplicated points are consumed at once.
+			for i := range indexes {
+				for indexes[i] < functions[i].Len() {
+					if on, _ := functions[i].At(indexes[i]); !on.Equal(m) {
+						break
+					}
 					// Updates and consume this value
 					indexes[i]++
 				}
@@ -145,3 +145,68 @@ func TestAdd(t *testing.T) {
 		}
 	}
 }
+
+// TestConstant checks that the constant is defined at all times.
+func TestConstant(t *testing.T) {
+	f := timeserie.Constant(3)
+	for _, on := range []time.Time{{}, d0, d2} {
+		if x := f.F(on); x != 3 {
+			t.Errorf("Constant(3).F(%v)=%v want 3", on, x)
+		}
+	}
+}
+
+// TestIterate_duplicates checks that duplicated times are yielded once.
+func TestIterate_duplicates(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(d0, 1.0)
+	s.Append(d0, 2.0)
+	s.Append(d1, 3.0)
+	f := timeserie.New(s, timeserie.ModeNullset)
+
+	x := slices.AppendSeq([]time.Time{}, timeserie.Iterate(f))
+	if len(x) != 2 {
+		t.Errorf("Iterate({0,0,1}) = %v want [%v, %v]", x, d0, d1)
+	}
+}
+
+// TestFunction_F_linear checks the linear interpolation.
+func TestFunction_F_linear(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(d0, 1.0)
+	s.Append(d1, 3.0)
+	f := timeserie.New(s, timeserie.ModeLinear)
+
+	if x := f.F(d0.Add(12 * time.Hour)); x != 2.0 {
+		t.Errorf("linear.F(d0+12h)=%v want %v", x, 2.0)
+	}
+	if x := f.F(d1); x != 3.0 {
+		t.Errorf("linear.F(d1)=%v want %v", x, 3.0)
+	}
+	if x := f.F(d2); !math.IsNaN(x) {
+		t.Errorf("linear.F(d2)=%v want %v", x, math.NaN())
+	}
+}
+
+// TestAdd_linear checks that a linear function is interpolated on the other support events.
+func TestAdd_linear(t *testing.T) {
+	s1 := new(timeserie.Support)
+	s1.Append(d0, 0.0)
+	s1.Append(d2, 2.0)
+
+	s2 := new(timeserie.Support)
+	s2.Append(d0, 1.0)
+	s2.Append(d1, 1.0)
+	s2.Append(d2, 1.0)
+
+	x := timeserie.Add(timeserie.New(s1, timeserie.ModeLinear), timeserie.New(s2, timeserie.ModeStep))
+	if x.Mode() != timeserie.ModeStep {
+		t.Errorf("linear+step.Mode()=%v want %v", x.Mode(), timeserie.ModeStep)
+	}
+	want := []float64{1, 2, 3}
+	for i, w := range want {
+		if _, v := x.At(i); v != w {
+			t.Errorf("(linear+step)[%d]=%v want %v", i, v, w)
+		}
+	}
+}
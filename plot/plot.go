@@ -0,0 +1,223 @@
+// Package plot renders timeserie Functions as SVG charts.
+//
+// Functions are drawn according to their mode: a line for ModeLinear, a staircase for ModeStep and
+// markers for ModeNullset. The output is deterministic so that it can be compared with golden files.
+package plot
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"iter"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// Axis identifies a y-axis.
+type Axis int
+
+const (
+	Left  Axis = iota // Left y-axis.
+	Right             // Right y-axis.
+)
+
+// palette is the list of colors used by series, in order.
+var palette = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f"}
+
+// series is a named function drawn on a chart.
+type series struct {
+	name string
+	f    *timeserie.Function
+	axis Axis
+}
+
+// Chart is a chart of functions over time.
+type Chart struct {
+	Title         string
+	Width, Height int // Size in pixels.
+
+	series []series
+}
+
+// New creates an empty chart of the given size.
+func New(title string, width, height int) *Chart {
+	return &Chart{Title: title, Width: width, Height: height}
+}
+
+// Add a function to the chart, on the given y-axis.
+func (c *Chart) Add(name string, f *timeserie.Function, axis Axis) {
+	c.series = append(c.series, series{name: name, f: f, axis: axis})
+}
+
+const (
+	margin = 50 // margin around the plot area, in pixels.
+	ticks  = 8  // maximum number of ticks on an axis.
+)
+
+// SVG writes the chart as an SVG document.
+func (c *Chart) SVG(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10">`+"\n", c.Width, c.Height, c.Width, c.Height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", c.Width, c.Height)
+	if c.Title != "" {
+		fmt.Fprintf(&b, `<text x="%d" y="20" text-anchor="middle" font-size="14">%s</text>`+"\n", c.Width/2, html.EscapeString(c.Title))
+	}
+	x0, x1 := float64(margin), float64(c.Width-margin)
+	y0, y1 := float64(c.Height-margin), float64(margin)
+
+	from, to, ok := c.timeRange()
+	if !ok {
+		for i, s := range c.series {
+			legend(&b, i, s.name, x1)
+		}
+		b.WriteString("</svg>\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	x := func(t time.Time) float64 {
+		return x0 + (x1-x0)*float64(t.Sub(from))/float64(to.Sub(from))
+	}
+
+	// time axis
+	fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="black"/>`+"\n", num(x0), num(y0), num(x1), num(y0))
+	times, format := timeTicks(from, to, ticks)
+	for _, t := range times {
+		fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="black"/>`+"\n", num(x(t)), num(y0), num(x(t)), num(y0+4))
+		fmt.Fprintf(&b, `<text x="%s" y="%s" text-anchor="middle">%s</text>`+"\n", num(x(t)), num(y0+15), t.Format(format))
+	}
+
+	// y axes
+	var ys [2]func(float64) float64
+	for axis := Left; axis <= Right; axis++ {
+		lo, hi, ok := c.valueRange(axis)
+		if !ok {
+			continue
+		}
+		values := valueTicks(lo, hi, ticks)
+		if len(values) == 0 {
+			continue
+		}
+		lo, hi = values[0], values[len(values)-1]
+		ys[axis] = func(v float64) float64 { return y0 + (y1-y0)*(v-lo)/(hi-lo) }
+		xa, dx, anchor := x0, -4.0, "end"
+		if axis == Right {
+			xa, dx, anchor = x1, 4.0, "start"
+		}
+		fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="black"/>`+"\n", num(xa), num(y0), num(xa), num(y1))
+		for _, v := range values {
+			y := ys[axis](v)
+			fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="black"/>`+"\n", num(xa), num(y), num(xa+dx), num(y))
+			fmt.Fprintf(&b, `<text x="%s" y="%s" text-anchor="%s">%s</text>`+"\n", num(xa+2*dx), num(y+3), anchor, strconv.FormatFloat(v, 'g', 6, 64))
+		}
+	}
+
+	// series
+	for i, s := range c.series {
+		color := palette[i%len(palette)]
+		y := ys[s.axis]
+		legend(&b, i, s.name, x1) // even for series without points.
+		if y == nil {
+			continue // no finite values on the axis.
+		}
+		var points []string
+		switch s.f.Mode() {
+		case timeserie.ModeLinear:
+			for t, v := range finite(s.f) {
+				points = append(points, num(x(t))+","+num(y(v)))
+			}
+			fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" points="%s"/>`+"\n", color, strings.Join(points, " "))
+		case timeserie.ModeStep:
+			prev := math.NaN()
+			for t, v := range finite(s.f) {
+				if !math.IsNaN(prev) {
+					points = append(points, num(x(t))+","+num(y(prev)))
+				}
+				points = append(points, num(x(t))+","+num(y(v)))
+				prev = v
+			}
+			points = append(points, num(x1)+","+num(y(prev)))
+			fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" points="%s"/>`+"\n", color, strings.Join(points, " "))
+		default:
+			for t, v := range finite(s.f) {
+				fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="2" fill="%s"/>`+"\n", num(x(t)), num(y(v)), color)
+			}
+		}
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// legend writes the legend entry of the i-th series, right aligned on 'x1'.
+func legend(b *strings.Builder, i int, name string, x1 float64) {
+	ly := float64(margin + 12*i)
+	fmt.Fprintf(b, `<rect x="%s" y="%s" width="8" height="8" fill="%s"/>`+"\n", num(x1-100), num(ly-8), palette[i%len(palette)])
+	fmt.Fprintf(b, `<text x="%s" y="%s">%s</text>`+"\n", num(x1-88), num(ly), html.EscapeString(name))
+}
+
+// timeRange returns the time range of all series, ok is false if there are no points.
+func (c *Chart) timeRange() (from, to time.Time, ok bool) {
+	for _, s := range c.series {
+		if s.f.Len() == 0 {
+			continue
+		}
+		first, _ := s.f.At(0)
+		last, _ := s.f.At(s.f.Len() - 1)
+		if !ok || first.Before(from) {
+			from = first
+		}
+		if !ok || last.After(to) {
+			to = last
+		}
+		ok = true
+	}
+	if ok && !to.After(from) {
+		from, to = from.Add(-timeserie.Day), to.Add(timeserie.Day)
+	}
+	return from, to, ok
+}
+
+// valueRange returns the range of finite values of the series on 'axis', ok is false if there are
+// none.
+func (c *Chart) valueRange(axis Axis) (lo, hi float64, ok bool) {
+	for _, s := range c.series {
+		if s.axis != axis {
+			continue
+		}
+		for _, v := range finite(s.f) {
+			if !ok || v < lo {
+				lo = v
+			}
+			if !ok || v > hi {
+				hi = v
+			}
+			ok = true
+		}
+	}
+	return lo, hi, ok
+}
+
+// finite returns the points of 'f' with a finite value, non-finite values cannot be drawn.
+func finite(f *timeserie.Function) iter.Seq2[time.Time, float64] {
+	return func(yield func(time.Time, float64) bool) {
+		for t, v := range f.Values() {
+			if !math.IsInf(v, 0) && !math.IsNaN(v) && !yield(t, v) {
+				return
+			}
+		}
+	}
+}
+
+// num formats a number with a fixed precision, for deterministic output.
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
@@ -0,0 +1,123 @@
+package plot_test
+
+import (
+	"bytes"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/plot"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// golden compares 'got' with the golden file 'name', or updates it.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch, run with -update to see the difference", name)
+	}
+}
+
+// series returns a daily support from 2000-1-1 with 'n' values computed by 'f'.
+func series(n int, f func(i int) float64) *timeserie.Support {
+	s := new(timeserie.Support)
+	for i := 0; i < n; i++ {
+		s.Append(timeserie.DayDate(2000, 1, 1+i), f(i))
+	}
+	return s
+}
+
+// TestChart_SVG renders all modes on two axes.
+func TestChart_SVG(t *testing.T) {
+	c := plot.New("Modes & axes", 640, 320)
+	c.Add("linear", timeserie.New(series(90, func(i int) float64 { return math.Sin(float64(i) / 10) }), timeserie.ModeLinear), plot.Left)
+	c.Add("step", timeserie.New(series(90, func(i int) float64 { return float64(i / 30) }), timeserie.ModeStep), plot.Left)
+	c.Add("nullset", timeserie.New(series(90, func(i int) float64 { return 1000 * float64(i%7) }), timeserie.ModeNullset), plot.Right)
+
+	var b bytes.Buffer
+	if err := c.SVG(&b); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "modes.svg", b.Bytes())
+}
+
+// TestChart_SVG_hours checks the time ticks on a short range.
+func TestChart_SVG_hours(t *testing.T) {
+	s := new(timeserie.Support)
+	for i := 0; i < 6; i++ {
+		s.Append(timeserie.DayDate(2000, 1, 1).Add(time.Duration(i)*time.Hour), float64(i))
+	}
+	c := plot.New("", 320, 200)
+	c.Add("hours", timeserie.New(s, timeserie.ModeLinear), plot.Left)
+
+	var b bytes.Buffer
+	if err := c.SVG(&b); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "hours.svg", b.Bytes())
+}
+
+// TestChart_SVG_empty checks that series without points still have a legend entry.
+func TestChart_SVG_empty(t *testing.T) {
+	c := plot.New("", 320, 200)
+	c.Add("full", timeserie.New(series(10, func(i int) float64 { return float64(i) }), timeserie.ModeLinear), plot.Left)
+	c.Add("empty", timeserie.New(new(timeserie.Support), timeserie.ModeLinear), plot.Left)
+
+	var b bytes.Buffer
+	if err := c.SVG(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b.Bytes(), []byte(">empty</text>")) {
+		t.Errorf("SVG() has no legend for the empty series")
+	}
+
+	c = plot.New("", 320, 200)
+	c.Add("empty", timeserie.New(new(timeserie.Support), timeserie.ModeLinear), plot.Left)
+	b.Reset()
+	if err := c.SVG(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b.Bytes(), []byte(">empty</text>")) {
+		t.Errorf("SVG() has no legend for the only empty series")
+	}
+}
+
+// TestChart_SVG_infinite checks that non-finite values are not drawn, and do not break the axes.
+func TestChart_SVG_infinite(t *testing.T) {
+	for name, values := range map[string][]float64{
+		"inf":  {1, math.Inf(1)},
+		"only": {math.Inf(-1)},
+		"wide": {-math.MaxFloat64, math.MaxFloat64},
+	} {
+		s := new(timeserie.Support)
+		for i, v := range values {
+			s.Append(timeserie.DayDate(2000, 1, 1+i), v)
+		}
+		for _, mode := range []timeserie.Mode{timeserie.ModeNullset, timeserie.ModeStep, timeserie.ModeLinear} {
+			c := plot.New("", 320, 200)
+			c.Add(name, timeserie.New(s, mode), plot.Left)
+			var b bytes.Buffer
+			if err := c.SVG(&b); err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(b.Bytes(), []byte("Inf")) || bytes.Contains(b.Bytes(), []byte("NaN")) {
+				t.Errorf("SVG(%s) draws non-finite values:\n%s", name, b.String())
+			}
+		}
+	}
+}
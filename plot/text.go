@@ -0,0 +1,187 @@
+package plot
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// columns computes the range of values of 'f' in 'n' columns of equal duration. Columns with
+// several points hold their minimum and maximum, columns without points hold the function value
+// for ModeStep and ModeLinear, and NaN for ModeNullset. Non-finite values are ignored.
+func columns(f *timeserie.Function, n int) (lo, hi []float64, from, to time.Time) {
+	lo, hi = make([]float64, n), make([]float64, n)
+	for i := range lo {
+		lo[i], hi[i] = math.NaN(), math.NaN()
+	}
+	if f.Len() == 0 || n == 0 {
+		return lo, hi, from, to
+	}
+	from, _ = f.At(0)
+	to, _ = f.At(f.Len() - 1)
+	span := to.Sub(from)
+	column := func(t time.Time) int {
+		if span == 0 {
+			return 0
+		}
+		return min(n-1, int(float64(t.Sub(from))/float64(span)*float64(n)))
+	}
+	for t, v := range finite(f) {
+		i := column(t)
+		if math.IsNaN(lo[i]) || v < lo[i] {
+			lo[i] = v
+		}
+		if math.IsNaN(hi[i]) || v > hi[i] {
+			hi[i] = v
+		}
+	}
+	if f.Mode() == timeserie.ModeNullset {
+		return lo, hi, from, to
+	}
+	for i := range lo {
+		if math.IsNaN(lo[i]) {
+			t := from.Add(time.Duration(float64(span) * (float64(i) + 0.5) / float64(n)))
+			if v := f.F(t); !math.IsInf(v, 0) {
+				lo[i], hi[i] = v, v
+			}
+		}
+	}
+	return lo, hi, from, to
+}
+
+// extent returns the min and max of all defined values.
+func extent(lo, hi []float64) (float64, float64) {
+	a, b := math.Inf(1), math.Inf(-1)
+	for i := range lo {
+		if !math.IsNaN(lo[i]) {
+			a, b = math.Min(a, lo[i]), math.Max(b, hi[i])
+		}
+	}
+	return a, b
+}
+
+// scale returns the position of 'v' in [a, b], from 0 to 1, without overflowing on wide ranges.
+func scale(v, a, b float64) float64 { return (v/2 - a/2) / (b/2 - a/2) }
+
+// sparks are the characters of a sparkline, from the lowest to the highest.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline returns 'f' as a one line string of 'width' characters, using block characters.
+//
+// Each character is the maximum of the points in its column. Columns without a point are blank for
+// ModeNullset functions.
+func Sparkline(f *timeserie.Function, width int) string {
+	lo, hi, _, _ := columns(f, width)
+	a, b := extent(lo, hi)
+	var s strings.Builder
+	for i := range hi {
+		switch {
+		case math.IsNaN(hi[i]):
+			s.WriteRune(' ')
+		case b == a:
+			s.WriteRune(sparks[len(sparks)/2])
+		default:
+			s.WriteRune(sparks[int(math.Round(scale(hi[i], a, b)*float64(len(sparks)-1)))])
+		}
+	}
+	return s.String()
+}
+
+// grid is a matrix of dots.
+type grid [][]bool
+
+// draw returns a grid of 'w' x 'h' dots, each column spanning its range of values, and the range.
+func draw(f *timeserie.Function, w, h int) (g grid, a, b float64, from, to time.Time) {
+	lo, hi, from, to := columns(f, w)
+	a, b = extent(lo, hi)
+	g = make(grid, h)
+	for y := range g {
+		g[y] = make([]bool, w)
+	}
+	row := func(v float64) int {
+		if b == a {
+			return h / 2
+		}
+		return h - 1 - int(math.Round(scale(v, a, b)*float64(h-1)))
+	}
+	for x := range lo {
+		if math.IsNaN(lo[x]) {
+			continue
+		}
+		for y := row(hi[x]); y <= row(lo[x]); y++ {
+			g[y][x] = true
+		}
+	}
+	return g, a, b, from, to
+}
+
+// Braille returns 'f' as a chart of 'width' x 'height' characters, using braille patterns of 2x4
+// dots, with min and max labels and the date range.
+func Braille(f *timeserie.Function, width, height int) string {
+	g, a, b, from, to := draw(f, 2*width, 4*height)
+	// braille dot bits by position (x, y) in a 2x4 cell.
+	bits := [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+	lines := make([]string, height)
+	for row := range lines {
+		var s strings.Builder
+		for col := 0; col < width; col++ {
+			r := rune(0x2800)
+			for dy := range 4 {
+				for dx := range 2 {
+					if g[4*row+dy][2*col+dx] {
+						r |= bits[dy][dx]
+					}
+				}
+			}
+			s.WriteRune(r)
+		}
+		lines[row] = s.String()
+	}
+	return label(lines, width, a, b, from, to)
+}
+
+// ASCII returns 'f' as a chart of 'width' x 'height' characters, using '*' for points, with min and
+// max labels and the date range.
+func ASCII(f *timeserie.Function, width, height int) string {
+	g, a, b, from, to := draw(f, width, height)
+	lines := make([]string, height)
+	for y, r := range g {
+		var s strings.Builder
+		for _, dot := range r {
+			if dot {
+				s.WriteByte('*')
+			} else {
+				s.WriteByte(' ')
+			}
+		}
+		lines[y] = s.String()
+	}
+	return label(lines, width, a, b, from, to)
+}
+
+// label adds the min and max labels in front of 'lines', and the date range below.
+func label(lines []string, width int, a, b float64, from, to time.Time) string {
+	if math.IsInf(a, 1) {
+		return "no data\n"
+	}
+	top, bottom := strconv.FormatFloat(b, 'g', 6, 64), strconv.FormatFloat(a, 'g', 6, 64)
+	pad := max(len(top), len(bottom))
+	var s strings.Builder
+	for i, line := range lines {
+		l := ""
+		switch i {
+		case 0:
+			l = top
+		case len(lines) - 1:
+			l = bottom
+		}
+		fmt.Fprintf(&s, "%*s │%s\n", pad, l, line)
+	}
+	start, end := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	fmt.Fprintf(&s, "%*s  %s%s%s\n", pad, "", start, strings.Repeat(" ", max(1, width-len(start)-len(end))), end)
+	return s.String()
+}
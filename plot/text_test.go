@@ -0,0 +1,85 @@
+package plot_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/plot"
+)
+
+// TestSparkline checks one character per point, and downsampling.
+func TestSparkline(t *testing.T) {
+	f := timeserie.New(series(8, func(i int) float64 { return float64(i) }), timeserie.ModeLinear)
+	if got, want := plot.Sparkline(f, 8), "▁▂▃▄▅▆▇█"; got != want {
+		t.Errorf("Sparkline(0..7, 8) = %q want %q", got, want)
+	}
+	if got, want := plot.Sparkline(f, 4), "▂▄▆█"; got != want {
+		t.Errorf("Sparkline(0..7, 4) = %q want %q", got, want)
+	}
+}
+
+// TestSparkline_nullset checks that columns without points are blank for nullset functions only.
+func TestSparkline_nullset(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(timeserie.DayDate(2000, 1, 1), 0)
+	s.Append(timeserie.DayDate(2000, 1, 4), 1)
+	if got, want := plot.Sparkline(timeserie.New(s, timeserie.ModeNullset), 4), "▁  █"; got != want {
+		t.Errorf("Sparkline(nullset) = %q want %q", got, want)
+	}
+	if got, want := plot.Sparkline(timeserie.New(s, timeserie.ModeStep), 4), "▁▁▁█"; got != want {
+		t.Errorf("Sparkline(step) = %q want %q", got, want)
+	}
+}
+
+// TestASCII checks the layout of the chart.
+func TestASCII(t *testing.T) {
+	f := timeserie.New(series(10, func(i int) float64 { return math.Abs(float64(i) - 4.5) }), timeserie.ModeLinear)
+	want := `
+4.5 │*        *
+    │ **    ** 
+    │   *  *   
+0.5 │    **    
+     2000-01-01 2000-01-10
+`
+	if got := plot.ASCII(f, 10, 4); got != strings.TrimPrefix(want, "\n") {
+		t.Errorf("ASCII() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestBraille checks the size of the chart.
+func TestBraille(t *testing.T) {
+	f := timeserie.New(series(100, func(i int) float64 { return math.Sin(float64(i) / 10) }), timeserie.ModeLinear)
+	lines := strings.Split(strings.TrimSuffix(plot.Braille(f, 20, 5), "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Braille(20, 5) has %v lines want 6", len(lines))
+	}
+	for _, line := range lines[:5] {
+		if _, chart, _ := strings.Cut(line, "│"); len([]rune(chart)) != 20 {
+			t.Errorf("Braille(20, 5) line %q has %v columns want 20", line, len([]rune(chart)))
+		}
+	}
+}
+
+// TestSparkline_infinite checks that non-finite values are ignored by text charts.
+func TestSparkline_infinite(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(timeserie.DayDate(2000, 1, 1), 1)
+	s.Append(timeserie.DayDate(2000, 1, 2), math.Inf(1))
+	s.Append(timeserie.DayDate(2000, 1, 3), 2)
+	f := timeserie.New(s, timeserie.ModeNullset)
+	if got, want := plot.Sparkline(f, 3), "▁ █"; got != want {
+		t.Errorf("Sparkline(1, +Inf, 2) = %q want %q", got, want)
+	}
+	wide := new(timeserie.Support)
+	wide.Append(timeserie.DayDate(2000, 1, 1), -math.MaxFloat64)
+	wide.Append(timeserie.DayDate(2000, 1, 2), math.MaxFloat64)
+	if got, want := plot.Sparkline(timeserie.New(wide, timeserie.ModeNullset), 2), "▁█"; got != want {
+		t.Errorf("Sparkline(wide) = %q want %q", got, want)
+	}
+	for _, mode := range []timeserie.Mode{timeserie.ModeStep, timeserie.ModeLinear} {
+		plot.ASCII(timeserie.New(s, mode), 10, 4)
+		plot.Braille(timeserie.New(s, mode), 10, 4)
+	}
+}
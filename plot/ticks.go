@@ -0,0 +1,82 @@
+package plot
+
+import (
+	"math"
+	"time"
+)
+
+// period is a calendar period used for time axis ticks.
+type period struct {
+	approx time.Duration             // approximate duration, used to choose the period.
+	format string                    // label format.
+	floor  func(time.Time) time.Time // start of the period containing t.
+	next   func(time.Time) time.Time // start of the next period.
+}
+
+// day truncates 't' to the start of its day.
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// periods are candidate periods, from the shortest to the longest.
+var periods = []period{
+	{time.Hour, "15:04", func(t time.Time) time.Time { return t.Truncate(time.Hour) }, func(t time.Time) time.Time { return t.Add(time.Hour) }},
+	{6 * time.Hour, "Jan 2 15:04", func(t time.Time) time.Time { return day(t).Add(time.Duration(t.Hour()/6*6) * time.Hour) }, func(t time.Time) time.Time { return t.Add(6 * time.Hour) }},
+	{24 * time.Hour, "Jan 2", day, func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{7 * 24 * time.Hour, "Jan 2", func(t time.Time) time.Time { return day(t).AddDate(0, 0, -(int(t.Weekday())+6)%7) }, func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }},
+	{30 * 24 * time.Hour, "Jan 2006", func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()) }, func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{91 * 24 * time.Hour, "Jan 2006", func(t time.Time) time.Time {
+		return time.Date(t.Year(), (t.Month()-1)/3*3+1, 1, 0, 0, 0, 0, t.Location())
+	}, func(t time.Time) time.Time { return t.AddDate(0, 3, 0) }},
+	{365 * 24 * time.Hour, "2006", func(t time.Time) time.Time { return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location()) }, func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+	{5 * 365 * 24 * time.Hour, "2006", func(t time.Time) time.Time { return time.Date(t.Year()/5*5, 1, 1, 0, 0, 0, 0, t.Location()) }, func(t time.Time) time.Time { return t.AddDate(5, 0, 0) }},
+	{10 * 365 * 24 * time.Hour, "2006", func(t time.Time) time.Time { return time.Date(t.Year()/10*10, 1, 1, 0, 0, 0, 0, t.Location()) }, func(t time.Time) time.Time { return t.AddDate(10, 0, 0) }},
+}
+
+// timeTicks returns the ticks in [from, to] for the shortest calendar period yielding at most 'n'
+// ticks, and the label format.
+func timeTicks(from, to time.Time, n int) ([]time.Time, string) {
+	p := periods[len(periods)-1]
+	for _, c := range periods {
+		if to.Sub(from)/c.approx <= time.Duration(n) {
+			p = c
+			break
+		}
+	}
+	var ticks []time.Time
+	t := p.floor(from)
+	if t.Before(from) {
+		t = p.next(t)
+	}
+	for ; !t.After(to); t = p.next(t) {
+		ticks = append(ticks, t)
+	}
+	return ticks, p.format
+}
+
+// valueTicks returns 'nice' ticks (1, 2 or 5 times a power of ten apart) covering [lo, hi] with at
+// most 'n' intervals. The first and last ticks are the covered range, there are none if it is
+// not finite.
+func valueTicks(lo, hi float64, n int) []float64 {
+	if math.IsInf(hi-lo, 0) || math.IsNaN(hi-lo) {
+		return nil // too wide to be ticked.
+	}
+	if hi <= lo {
+		lo, hi = lo-1, hi+1
+	}
+	raw := (hi - lo) / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := 10 * mag
+	for _, f := range []float64{1, 2, 5} {
+		if f*mag >= raw {
+			step = f * mag
+			break
+		}
+	}
+	var ticks []float64
+	for i := math.Floor(lo / step); i <= math.Ceil(hi/step); i++ {
+		ticks = append(ticks, i*step)
+	}
+	return ticks
+}
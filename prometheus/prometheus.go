@@ -0,0 +1,182 @@
+// Package prometheus exports supports in the Prometheus text exposition format, and ingests
+// Prometheus remote-write payloads into supports.
+//
+// Series are named after Prometheus metrics and labels, as in 'name{label="value",...}' with labels
+// in alphabetical order, or simply 'name' without labels.
+//
+// Ingested samples keep their millisecond timestamp, in UTC, and replace any previous point at the
+// same time.
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// Expose writes the latest value of each support as a gauge in the Prometheus text exposition
+// format. Invalid characters in metric and label names are replaced by '_'.
+func Expose(w io.Writer, dict map[string]*timeserie.Support) error {
+	metrics := make(map[string][]string) // exposed lines by metric name.
+	for key, s := range dict {
+		if s.Len() == 0 {
+			continue
+		}
+		metric, labels := parse(key)
+		_, v := s.At(s.Len() - 1)
+		metrics[metric] = append(metrics[metric], name(metric, labels)+" "+formatValue(v))
+	}
+	for _, metric := range slices.Sorted(maps.Keys(metrics)) {
+		lines := metrics[metric]
+		slices.Sort(lines)
+		if _, err := fmt.Fprintf(w, "# TYPE %s gauge\n", metric); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Ingest reads a remote-write payload (a snappy compressed WriteRequest protobuf message) into
+// 'dict'. It returns the number of samples ingested. Stale markers (NaN samples) are ignored.
+func Ingest(dict map[string]*timeserie.Support, r io.Reader) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read remote-write payload: %w", err)
+	}
+	if data, err = decode(data); err != nil {
+		return 0, fmt.Errorf("invalid remote-write payload: %w", err)
+	}
+	list, err := unmarshal(data)
+	if err != nil {
+		return 0, fmt.Errorf("invalid remote-write payload: %w", err)
+	}
+	var n int
+	for _, ts := range list {
+		var metric string
+		labels := make([]label, 0, len(ts.labels))
+		for _, l := range ts.labels {
+			if l.name == "__name__" {
+				metric = l.value
+			} else {
+				labels = append(labels, l)
+			}
+		}
+		if metric == "" {
+			return n, fmt.Errorf("invalid remote-write payload: series without '__name__' label")
+		}
+		key := name(metric, labels)
+		s, ok := dict[key]
+		if !ok {
+			s = new(timeserie.Support)
+			dict[key] = s
+		}
+		for _, x := range ts.samples {
+			if math.IsNaN(x.value) {
+				continue
+			}
+			s.Upsert(time.UnixMilli(x.timestamp).UTC(), x.value)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Marshal returns the remote-write payload of all points in 'dict'. The payload is snappy encoded
+// without compression.
+func Marshal(dict map[string]*timeserie.Support) []byte {
+	var list []series
+	for _, key := range slices.Sorted(maps.Keys(dict)) {
+		metric, labels := parse(key)
+		ts := series{labels: append([]label{{"__name__", metric}}, labels...)}
+		for on, v := range dict[key].Values() {
+			ts.samples = append(ts.samples, sample{v, on.UnixMilli()})
+		}
+		list = append(list, ts)
+	}
+	return encode(marshal(list))
+}
+
+// name returns the series name of a metric with labels.
+func name(metric string, labels []label) string {
+	if len(labels) == 0 {
+		return metric
+	}
+	labels = slices.Clone(labels)
+	slices.SortFunc(labels, func(a, b label) int { return strings.Compare(a.name, b.name) })
+	var b strings.Builder
+	b.WriteString(metric)
+	b.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", sanitize(l.name), escaper.Replace(l.value))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// escaper escapes label values.
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// parse returns the metric name and labels of a series name. Names that are not of the form
+// 'name{label="value",...}' are metric names, sanitized.
+func parse(key string) (string, []label) {
+	i := strings.IndexByte(key, '{')
+	if i < 0 || !strings.HasSuffix(key, "}") {
+		return sanitize(key), nil
+	}
+	var labels []label
+	rest := key[i+1 : len(key)-1]
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 || len(rest) < eq+2 || rest[eq+1] != '"' {
+			return sanitize(key), nil
+		}
+		quoted, err := strconv.QuotedPrefix(rest[eq+1:])
+		if err != nil {
+			return sanitize(key), nil
+		}
+		value, _ := strconv.Unquote(quoted)
+		labels = append(labels, label{sanitize(rest[:eq]), value})
+		rest = strings.TrimPrefix(rest[eq+1+len(quoted):], ",")
+	}
+	return sanitize(key[:i]), labels
+}
+
+// sanitize replaces characters that are invalid in metric names by '_'.
+func sanitize(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == ':' || i > 0 && '0' <= c && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
+
+// formatValue formats a sample value.
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,148 @@
+package prometheus_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/prometheus"
+)
+
+var (
+	d0 = timeserie.DayDate(2024, 1, 1)
+	d1 = timeserie.DayDate(2024, 1, 2)
+)
+
+// support returns a support with points at successive days from d0.
+func support(values ...float64) *timeserie.Support {
+	s := new(timeserie.Support)
+	for i, v := range values {
+		s.Append(d0.AddDate(0, 0, i), v)
+	}
+	return s
+}
+
+func TestExpose(t *testing.T) {
+	dict := map[string]*timeserie.Support{
+		"food":                                support(10, 12),
+		"my-series":                           support(1),
+		`http_requests{job="api",code="200"}`: support(5, 7),
+		`http_requests{code="500",job="api"}`: support(1),
+		"empty":                               new(timeserie.Support),
+	}
+	var b strings.Builder
+	if err := prometheus.Expose(&b, dict); err != nil {
+		t.Fatal(err)
+	}
+	want := `# TYPE food gauge
+food 12
+# TYPE http_requests gauge
+http_requests{code="200",job="api"} 7
+http_requests{code="500",job="api"} 1
+# TYPE my_series gauge
+my_series 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("Expose() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestIngest(t *testing.T) {
+	intraday := new(timeserie.Support)
+	intraday.Append(d0.Add(10*time.Hour), 1)
+	intraday.Append(d0.Add(18*time.Hour), 2)
+	intraday.Append(d1.Add(9*time.Hour), 3)
+	src := map[string]*timeserie.Support{
+		`up{instance="a\"b\\c"}`: support(1, 0, 1),
+		"load":                   intraday,
+	}
+	payload := prometheus.Marshal(src)
+
+	for _, p := range [][]byte{payload, compress(t, payload)} {
+		dict := make(map[string]*timeserie.Support)
+		n, err := prometheus.Ingest(dict, bytes.NewReader(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 6 {
+			t.Errorf("Ingest() = %d samples, want 6", n)
+		}
+		var b strings.Builder
+		timeserie.Format(&b, dict)
+		want := `{ "on":"24-1-1", "up{instance=\"a\\\"b\\\\c\"}":1}
+{ "on":"2024-01-01T10:00:00Z", "load":1}
+{ "on":"2024-01-01T18:00:00Z", "load":2}
+{ "on":"24-1-2", "up{instance=\"a\\\"b\\\\c\"}":0}
+{ "on":"2024-01-02T09:00:00Z", "load":3}
+{ "on":"24-1-3", "up{instance=\"a\\\"b\\\\c\"}":1}
+`
+		if got := b.String(); got != want {
+			t.Errorf("Ingest() =\n%s\nwant\n%s", got, want)
+		}
+	}
+
+	for name, p := range map[string]string{
+		"garbage":        "\x10garbage",
+		"too long":       "\x80\x80\x80\x80\x04", // 1<<30 bytes.
+		"long literal":   "\x01\x04ab",           // 2 bytes literal in a 1 byte block.
+		"long copy":      "\x02\x00a\x05\x01",    // 5 bytes copy in a 2 bytes block.
+		"invalid offset": "\x05\x00a\x01\x02",    // copy from 2 bytes back after 1 byte.
+	} {
+		if _, err := prometheus.Ingest(make(map[string]*timeserie.Support), strings.NewReader(p)); err == nil {
+			t.Errorf("Ingest(%s) succeeded, want an error", name)
+		}
+	}
+}
+
+// compress recompresses a snappy block made of a single literal using copies of previous data, to
+// exercise the decoder with real compressed payloads.
+func compress(t *testing.T, payload []byte) []byte {
+	t.Helper()
+	n, k := binary.Uvarint(payload)
+	if payload[k]>>2 != 61 {
+		t.Fatalf("unexpected payload tag %x", payload[k])
+	}
+	src := payload[k+3:]
+	if len(src) != int(n) {
+		t.Fatalf("payload is not a single literal")
+	}
+	dst := binary.AppendUvarint(nil, n)
+	lit := 0 // start of the pending literal.
+	flush := func(end int) {
+		for lit < end {
+			l := min(end-lit, 60)
+			dst = append(dst, byte(l-1)<<2)
+			dst = append(dst, src[lit:lit+l]...)
+			lit += l
+		}
+	}
+	for i := 0; i < len(src); {
+		// Longest match of at most 64 bytes at a previous position.
+		best, offset := 0, 0
+		for j := max(0, i-65535); j < i; j++ {
+			l := 0
+			for l < 64 && i+l < len(src) && src[j+l] == src[i+l] {
+				l++
+			}
+			if l > best {
+				best, offset = l, i-j
+			}
+		}
+		if best < 4 {
+			i++
+			continue
+		}
+		flush(i)
+		dst = append(dst, byte(best-1)<<2|2, byte(offset), byte(offset>>8))
+		i += best
+		lit = i
+	}
+	flush(len(src))
+	if len(dst) >= len(payload) {
+		t.Fatalf("payload not compressed: %d >= %d bytes", len(dst), len(payload))
+	}
+	return dst
+}
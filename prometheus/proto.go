@@ -0,0 +1,154 @@
+package prometheus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Protocol buffers wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// label is a Prometheus label.
+type label struct{ name, value string }
+
+// sample is a Prometheus sample: a value at a time in unix milliseconds.
+type sample struct {
+	value     float64
+	timestamp int64
+}
+
+// series is a Prometheus time series of a remote-write request.
+type series struct {
+	labels  []label
+	samples []sample
+}
+
+// fields calls 'fn' for each field of the protobuf message 'b'. Varint and fixed fields are passed
+// in 'v', length-delimited ones in 'data'.
+func fields(b []byte, fn func(num int, typ int, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errors.New("protobuf: invalid field key")
+		}
+		b = b[n:]
+		num, typ := int(key>>3), int(key&7)
+		var v uint64
+		var data []byte
+		switch typ {
+		case wireVarint:
+			if v, n = binary.Uvarint(b); n <= 0 {
+				return errors.New("protobuf: invalid varint")
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errors.New("protobuf: truncated fixed64")
+			}
+			v, b = binary.LittleEndian.Uint64(b), b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errors.New("protobuf: truncated fixed32")
+			}
+			v, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return errors.New("protobuf: invalid length")
+			}
+			data, b = b[n:n+int(l)], b[n+int(l):]
+		default:
+			return fmt.Errorf("protobuf: unsupported wire type %d", typ)
+		}
+		if err := fn(num, typ, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshal decodes a remote-write WriteRequest message.
+func unmarshal(b []byte) ([]series, error) {
+	var list []series
+	err := fields(b, func(num, typ int, _ uint64, data []byte) error {
+		if num != 1 || typ != wireBytes { // metadata and unknown fields are ignored.
+			return nil
+		}
+		var s series
+		err := fields(data, func(num, typ int, _ uint64, data []byte) error {
+			if typ != wireBytes {
+				return nil
+			}
+			switch num {
+			case 1:
+				var l label
+				err := fields(data, func(num, typ int, _ uint64, data []byte) error {
+					switch {
+					case num == 1 && typ == wireBytes:
+						l.name = string(data)
+					case num == 2 && typ == wireBytes:
+						l.value = string(data)
+					}
+					return nil
+				})
+				s.labels = append(s.labels, l)
+				return err
+			case 2:
+				var x sample
+				err := fields(data, func(num, typ int, v uint64, _ []byte) error {
+					switch {
+					case num == 1 && typ == wireFixed64:
+						x.value = math.Float64frombits(v)
+					case num == 2 && typ == wireVarint:
+						x.timestamp = int64(v)
+					}
+					return nil
+				})
+				s.samples = append(s.samples, x)
+				return err
+			}
+			return nil
+		})
+		list = append(list, s)
+		return err
+	})
+	return list, err
+}
+
+// appendBytes appends a length-delimited field.
+func appendBytes(b []byte, num int, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(num<<3|wireBytes))
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// marshal encodes a remote-write WriteRequest message.
+func marshal(list []series) []byte {
+	var b []byte
+	for _, s := range list {
+		var ts []byte
+		for _, l := range s.labels {
+			var lb []byte
+			lb = appendBytes(lb, 1, []byte(l.name))
+			lb = appendBytes(lb, 2, []byte(l.value))
+			ts = appendBytes(ts, 1, lb)
+		}
+		for _, x := range s.samples {
+			var sb []byte
+			sb = binary.AppendUvarint(sb, 1<<3|wireFixed64)
+			sb = binary.LittleEndian.AppendUint64(sb, math.Float64bits(x.value))
+			sb = binary.AppendUvarint(sb, 2<<3|wireVarint)
+			sb = binary.AppendUvarint(sb, uint64(x.timestamp))
+			ts = appendBytes(ts, 2, sb)
+		}
+		b = appendBytes(b, 1, ts)
+	}
+	return b
+}
@@ -0,0 +1,104 @@
+package prometheus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// maxDecoded is the maximum length of a decoded block, in bytes.
+const maxDecoded = 64 << 20
+
+// decode decompresses a snappy block, as used by Prometheus remote-write. Blocks longer than
+// maxDecoded are rejected.
+func decode(src []byte) ([]byte, error) {
+	n, k := binary.Uvarint(src)
+	if k <= 0 {
+		return nil, errors.New("snappy: invalid length")
+	}
+	if n > maxDecoded {
+		return nil, fmt.Errorf("snappy: decoded length %d exceeds %d", n, maxDecoded)
+	}
+	src = src[k:]
+	dst := make([]byte, 0, n)
+	for len(src) > 0 {
+		tag := src[0]
+		src = src[1:]
+		var length, offset int
+		switch tag & 3 {
+		case 0: // literal
+			length = int(tag >> 2)
+			if length >= 60 {
+				w := length - 59 // number of bytes holding the length.
+				if len(src) < w {
+					return nil, errors.New("snappy: truncated literal")
+				}
+				length = 0
+				for i := range w {
+					length |= int(src[i]) << (8 * i)
+				}
+				src = src[w:]
+			}
+			length++
+			if len(src) < length {
+				return nil, errors.New("snappy: truncated literal")
+			}
+			if len(dst)+length > int(n) {
+				return nil, errors.New("snappy: literal exceeds the decoded length")
+			}
+			dst, src = append(dst, src[:length]...), src[length:]
+			continue
+		case 1: // copy with 1-byte offset
+			if len(src) < 1 {
+				return nil, errors.New("snappy: truncated copy")
+			}
+			length = 4 + int(tag>>2)&7
+			offset = int(tag&0xe0)<<3 | int(src[0])
+			src = src[1:]
+		case 2: // copy with 2-byte offset
+			if len(src) < 2 {
+				return nil, errors.New("snappy: truncated copy")
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src))
+			src = src[2:]
+		case 3: // copy with 4-byte offset
+			if len(src) < 4 {
+				return nil, errors.New("snappy: truncated copy")
+			}
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src))
+			src = src[4:]
+		}
+		if offset <= 0 || offset > len(dst) {
+			return nil, fmt.Errorf("snappy: invalid copy offset %d", offset)
+		}
+		if len(dst)+length > int(n) {
+			return nil, errors.New("snappy: copy exceeds the decoded length")
+		}
+		// Copies may overlap their own output, hence byte per byte.
+		start := len(dst) - offset
+		for i := range length {
+			dst = append(dst, dst[start+i])
+		}
+	}
+	if len(dst) != int(n) {
+		return nil, fmt.Errorf("snappy: decoded %d bytes, expected %d", len(dst), n)
+	}
+	return dst, nil
+}
+
+// encode compresses 'src' as a snappy block made of literals only: valid but not compressed.
+func encode(src []byte) []byte {
+	dst := binary.AppendUvarint(nil, uint64(len(src)))
+	for len(src) > 0 {
+		n := min(len(src), 1<<16)
+		if n <= 60 {
+			dst = append(dst, byte(n-1)<<2)
+		} else {
+			dst = append(dst, 61<<2, byte(n-1), byte((n-1)>>8))
+		}
+		dst, src = append(dst, src[:n]...), src[n:]
+	}
+	return dst
+}
@@ -0,0 +1,307 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/expr"
+)
+
+// pos is a position in the query.
+type pos struct{ line, col int }
+
+// errorf returns an expr.Error at 'p'.
+func (p pos) errorf(format string, args ...any) error {
+	return &expr.Error{Line: p.line, Col: p.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// shift moves the position of an error in an expression starting at 'p'.
+func (p pos) shift(err error) error {
+	var e *expr.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	if e.Line == 1 {
+		e.Col += p.col - 1
+	}
+	e.Line += p.line - 1
+	return e
+}
+
+// kind is the kind of a token.
+type kind int
+
+const (
+	tEOF kind = iota
+	tIdent
+	tString
+	tOther // numbers and operators, only used in expressions.
+)
+
+type token struct {
+	kind   kind
+	text   string
+	pos    pos
+	offset int // rune offset in the query.
+}
+
+func (t token) String() string {
+	if t.kind == tEOF {
+		return "end of query"
+	}
+	return strconv.Quote(t.text)
+}
+
+// keyword returns true if the token is the keyword 'k', case insensitive.
+func (t token) keyword(k string) bool { return t.kind == tIdent && strings.EqualFold(t.text, k) }
+
+// lex splits the query into tokens.
+func lex(src []rune) ([]token, error) {
+	var tokens []token
+	p := pos{1, 1}
+	advance := func(n int, i int) {
+		for _, r := range src[i : i+n] {
+			if r == '\n' {
+				p = pos{p.line + 1, 1}
+			} else {
+				p.col++
+			}
+		}
+	}
+	for i := 0; i < len(src); {
+		r := src[i]
+		start := p
+		n := 1
+		switch {
+		case unicode.IsSpace(r):
+			advance(1, i)
+			i++
+			continue
+		case r == '\'':
+			j := slices.Index(src[i+1:], '\'')
+			if j < 0 {
+				return nil, start.errorf("unterminated string")
+			}
+			n = j + 2
+			tokens = append(tokens, token{tString, string(src[i+1 : i+1+j]), start, i})
+		case unicode.IsLetter(r) || r == '_':
+			for i+n < len(src) && (unicode.IsLetter(src[i+n]) || unicode.IsDigit(src[i+n]) || src[i+n] == '_' || src[i+n] == '.') {
+				n++
+			}
+			tokens = append(tokens, token{tIdent, string(src[i : i+n]), start, i})
+		case (r == '<' || r == '>') && i+1 < len(src) && src[i+1] == '=':
+			n = 2
+			tokens = append(tokens, token{tOther, string(src[i : i+n]), start, i})
+		default:
+			tokens = append(tokens, token{tOther, string(r), start, i})
+		}
+		advance(n, i)
+		i += n
+	}
+	return append(tokens, token{tEOF, "", p, len(src)}), nil
+}
+
+// parser is a recursive descent parser.
+type parser struct {
+	src    []rune
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token { return p.tokens[p.i] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tEOF {
+		p.i++
+	}
+	return t
+}
+
+// keyword consumes the keyword 'k'.
+func (p *parser) keyword(k string) error {
+	if t := p.next(); !t.keyword(k) {
+		return t.pos.errorf("expected %s got %v", k, t)
+	}
+	return nil
+}
+
+// clauses are the keywords that end a select item.
+var clauses = []string{"FROM", "WHERE", "GROUP", "AS"}
+
+// expression consumes tokens up to the end of the select item, and parses them as an expression.
+func (p *parser) expression() (*expr.Expr, string, pos, error) {
+	start := p.peek()
+	depth := 0
+	for {
+		t := p.peek()
+		if t.kind == tEOF || (depth == 0 && (t.text == "," || t.text == ")")) ||
+			slices.ContainsFunc(clauses, t.keyword) {
+			break
+		}
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		p.next()
+	}
+	text := strings.TrimSpace(string(p.src[start.offset:p.peek().offset]))
+	if text == "" {
+		return nil, "", start.pos, start.pos.errorf("expected an expression got %v", start)
+	}
+	x, err := expr.ParseExpr(text)
+	if err != nil {
+		return nil, "", start.pos, start.pos.shift(err)
+	}
+	return x, text, start.pos, nil
+}
+
+// item parses: [aggregate '('] expression [')'] [AS name]
+func (p *parser) item() (item, error) {
+	var it item
+	if t := p.peek(); t.kind == tIdent && p.tokens[p.i+1].text == "(" {
+		if _, ok := aggregates[strings.ToLower(t.text)]; ok {
+			it.aggregate = strings.ToLower(t.text)
+			p.next()
+			p.next()
+		}
+	}
+	x, text, at, err := p.expression()
+	if err != nil {
+		return it, err
+	}
+	it.x, it.name, it.at = x, text, at
+	if it.aggregate != "" {
+		if t := p.next(); t.text != ")" {
+			return it, t.pos.errorf("expected \")\" got %v", t)
+		}
+		it.name = fmt.Sprintf("%s(%s)", it.aggregate, text)
+	}
+	if p.peek().keyword("AS") {
+		p.next()
+		t := p.next()
+		if t.kind != tIdent {
+			return it, t.pos.errorf("expected a column name got %v", t)
+		}
+		it.name = t.text
+	}
+	return it, nil
+}
+
+// date parses a date string.
+func (p *parser) date() (time.Time, error) {
+	t := p.next()
+	if t.kind != tString {
+		return time.Time{}, t.pos.errorf("expected a date as 'YYYY-MM-DD' got %v", t)
+	}
+	d, err := time.Parse(time.DateOnly, t.text)
+	if err != nil {
+		return time.Time{}, t.pos.errorf("invalid date %q, expected 'YYYY-MM-DD'", t.text)
+	}
+	return d, nil
+}
+
+// cond parses: on BETWEEN date AND date | on op date
+func (p *parser) cond() ([]cond, error) {
+	if t := p.next(); !t.keyword("on") {
+		return nil, t.pos.errorf("expected a condition on 'on' got %v", t)
+	}
+	t := p.next()
+	if t.keyword("BETWEEN") {
+		from, err := p.date()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.keyword("AND"); err != nil {
+			return nil, err
+		}
+		to, err := p.date()
+		if err != nil {
+			return nil, err
+		}
+		return []cond{{">=", from}, {"<=", to}}, nil
+	}
+	switch t.text {
+	case "=", "<", "<=", ">", ">=":
+	default:
+		return nil, t.pos.errorf("expected BETWEEN or a comparison got %v", t)
+	}
+	d, err := p.date()
+	if err != nil {
+		return nil, err
+	}
+	return []cond{{t.text, d}}, nil
+}
+
+// Parse parses a query.
+func Parse(src string) (*Query, error) {
+	runes := []rune(src)
+	tokens, err := lex(runes)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: runes, tokens: tokens}
+	q := new(Query)
+	if err := p.keyword("SELECT"); err != nil {
+		return nil, err
+	}
+	for {
+		it, err := p.item()
+		if err != nil {
+			return nil, err
+		}
+		q.items = append(q.items, it)
+		if p.peek().text != "," {
+			break
+		}
+		p.next()
+	}
+	if p.peek().keyword("FROM") {
+		p.next()
+		t := p.next()
+		if t.kind != tIdent {
+			return nil, t.pos.errorf("expected a dump name got %v", t)
+		}
+		q.from = t.text
+	}
+	if p.peek().keyword("WHERE") {
+		p.next()
+		for {
+			conds, err := p.cond()
+			if err != nil {
+				return nil, err
+			}
+			q.where = append(q.where, conds...)
+			if !p.peek().keyword("AND") {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.peek().keyword("GROUP") {
+		p.next()
+		if err := p.keyword("BY"); err != nil {
+			return nil, err
+		}
+		t := p.next()
+		if _, ok := timeserie.Periods[strings.ToLower(t.text)]; t.kind != tIdent || !ok {
+			return nil, t.pos.errorf("expected day, week, month, quarter or year got %v", t)
+		}
+		q.groupBy = strings.ToLower(t.text)
+		if slices.ContainsFunc(q.items, func(it item) bool { return it.aggregate == "" }) {
+			return nil, t.pos.errorf("GROUP BY requires all selected items to be aggregates")
+		}
+	}
+	if t := p.next(); t.kind != tEOF {
+		return nil, t.pos.errorf("unexpected %v", t)
+	}
+	return q, nil
+}
@@ -0,0 +1,182 @@
+// Package query evaluates SQL-like queries over value change dumps.
+//
+// A query selects expressions over series, optionally aggregated by calendar period:
+//
+//	SELECT sum(food), avg(income - expenses) AS saving FROM dump
+//	WHERE on BETWEEN '2024-01-01' AND '2024-12-31'
+//	GROUP BY month
+//
+// Select items are expressions of package expr, optionally wrapped in an aggregate: sum, avg, min,
+// max, count, first or last. Without GROUP BY, aggregates are computed over the whole selection and
+// reported at its first time. GROUP BY accepts day, week, month, quarter or year, and groups are
+// reported at the first day of their period.
+//
+// The WHERE clause filters times with conditions on 'on' combined with AND: BETWEEN two dates
+// (included), or a comparison (=, <, <=, >, >=) with a date. Dates are written 'YYYY-MM-DD'.
+//
+// The FROM clause is optional and only names the queried dump.
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/expr"
+)
+
+// item is a selected expression.
+type item struct {
+	name      string // column name.
+	aggregate string // aggregate function, or empty.
+	x         *expr.Expr
+	at        pos // position of the expression in the query.
+}
+
+// cond is a condition on times.
+type cond struct {
+	op   string // one of = < <= > >=
+	date time.Time
+}
+
+func (c cond) accept(t time.Time) bool {
+	switch c.op {
+	case "=":
+		return t.Equal(c.date)
+	case "<":
+		return t.Before(c.date)
+	case "<=":
+		return !t.After(c.date)
+	case ">":
+		return t.After(c.date)
+	}
+	return !t.Before(c.date) // ">="
+}
+
+// Query is a parsed query.
+type Query struct {
+	items   []item
+	from    string
+	where   []cond
+	groupBy string
+}
+
+// aggregates are the aggregate functions.
+var aggregates = map[string]func(values []float64) float64{
+	"sum": func(values []float64) float64 {
+		var s float64
+		for _, v := range values {
+			s += v
+		}
+		return s
+	},
+	"avg": func(values []float64) float64 {
+		var s float64
+		for _, v := range values {
+			s += v
+		}
+		return s / float64(len(values))
+	},
+	"min":   func(values []float64) float64 { return slices.Min(values) },
+	"max":   func(values []float64) float64 { return slices.Max(values) },
+	"count": func(values []float64) float64 { return float64(len(values)) },
+	"first": func(values []float64) float64 { return values[0] },
+	"last":  func(values []float64) float64 { return values[len(values)-1] },
+}
+
+// From returns the name of the queried dump, or an empty string.
+func (q *Query) From() string { return q.from }
+
+// Eval evaluates the query on 'dict' and returns a Frame with a column per selected item.
+func (q *Query) Eval(dict map[string]*timeserie.Support) (*timeserie.Frame, error) {
+	names := make([]string, len(q.items))
+	functions := make([]*timeserie.Function, len(q.items))
+	for i, it := range q.items {
+		f, err := it.x.Eval(dict)
+		if err != nil {
+			return nil, it.at.shift(err)
+		}
+		s := q.filter(&f.Support)
+		if it.aggregate != "" {
+			s = q.aggregate(s, aggregates[it.aggregate])
+		}
+		names[i], functions[i] = it.name, timeserie.New(s, timeserie.ModeNullset)
+	}
+	return timeserie.NewFrame(timeserie.JoinOuter, names, functions...)
+}
+
+// filter returns the points of 's' accepted by the WHERE clause.
+func (q *Query) filter(s *timeserie.Support) *timeserie.Support {
+	res := new(timeserie.Support)
+	for t, v := range s.Values() {
+		if !slices.ContainsFunc(q.where, func(c cond) bool { return !c.accept(t) }) {
+			res.Append(t, v)
+		}
+	}
+	return res
+}
+
+// aggregate returns the aggregated values of 's' by group.
+func (q *Query) aggregate(s *timeserie.Support, agg func([]float64) float64) *timeserie.Support {
+	res := new(timeserie.Support)
+	if s.Len() == 0 {
+		return res
+	}
+	first, _ := s.At(0)
+	last, _ := s.At(s.Len() - 1)
+	starts := []time.Time{first}
+	if q.groupBy != "" {
+		// a period is at most a year long, so the first period starts within a year before.
+		starts = timeserie.Days(first.AddDate(-1, 0, 0), last.Add(timeserie.Day), timeserie.Periods[q.groupBy])
+		i := slices.IndexFunc(starts, func(t time.Time) bool { return t.After(first) })
+		if i > 0 {
+			starts = starts[i-1:]
+		}
+	}
+	var group []float64
+	g := 0
+	flush := func() {
+		if len(group) > 0 {
+			res.Append(starts[g], agg(group))
+		}
+		group = group[:0]
+	}
+	for t, v := range s.Values() {
+		for g+1 < len(starts) && !t.Before(starts[g+1]) {
+			flush()
+			g++
+		}
+		group = append(group, v)
+	}
+	flush()
+	return res
+}
+
+// String returns a normalized form of the query.
+func (q *Query) String() string {
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	for i, it := range q.items {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(it.name)
+	}
+	if q.from != "" {
+		fmt.Fprintf(&b, " FROM %s", q.from)
+	}
+	for i, c := range q.where {
+		if i == 0 {
+			b.WriteString(" WHERE ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		fmt.Fprintf(&b, "on %s '%s'", c.op, c.date.Format(time.DateOnly))
+	}
+	if q.groupBy != "" {
+		fmt.Fprintf(&b, " GROUP BY %s", q.groupBy)
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package query_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/query"
+)
+
+const dump = `
+{ "on":"23-12-31", "food":1, "income":1000}
+{ "on":"24-1-2", "food":10, "income":2000}
+{ "on":"24-1-20", "food":20}
+{ "on":"24-2-3", "food":5, "income":2000}
+{ "on":"24-4-1", "food":7}
+`
+
+// eval runs 'q' on the test dump and returns the result as a value change dump.
+func eval(t *testing.T, q string) (string, error) {
+	t.Helper()
+	dict := make(map[string]*timeserie.Support)
+	if err := timeserie.Load(dict, strings.NewReader(dump)); err != nil {
+		t.Fatal(err)
+	}
+	p, err := query.Parse(q)
+	if err != nil {
+		return "", err
+	}
+	fr, err := p.Eval(dict)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := timeserie.FormatCSV(&b, fr.Dict()); err != nil {
+		t.Fatal(err)
+	}
+	return b.String(), nil
+}
+
+// TestQuery checks selection, filtering and grouping.
+func TestQuery(t *testing.T) {
+	for _, test := range []struct {
+		query, want string
+	}{
+		{"SELECT food FROM dump WHERE on >= '2024-02-01'", "on,food\n24-2-3,5\n24-4-1,7\n"},
+		{"select sum(food) from dump where on between '2024-01-01' and '2024-12-31' group by month",
+			"on,sum(food)\n24-1-1,30\n24-2-1,5\n24-4-1,7\n"},
+		{"SELECT count(food) AS n, max(food) WHERE on > '2024-01-01' GROUP BY quarter",
+			"on,max(food),n\n24-1-1,20,3\n24-4-1,7,1\n"},
+		{"SELECT avg(food / 2) AS half", "on,half\n23-12-31,4.3\n"},
+		{"SELECT last(income - food) AS saving GROUP BY year", "on,saving\n23-1-1,999\n24-1-1,1995\n"},
+		{"SELECT sum(food) GROUP BY week WHERE on < '2024-02-01'", ""},
+	} {
+		got, err := eval(t, test.query)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%q error = nil want error", test.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q error: %v", test.query, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q =\n%s\nwant\n%s", test.query, got, test.want)
+		}
+	}
+}
+
+// TestErrors checks error positions.
+func TestErrors(t *testing.T) {
+	for _, test := range []struct {
+		query, want string
+	}{
+		{"SELEC food", `1:1: expected SELECT got "SELEC"`},
+		{"SELECT food +", "1:14: unexpected end of input"},
+		{"SELECT sum(fod)", `1:12: unknown series "fod"`},
+		{"SELECT food WHERE on > '2024-13-01'", `1:24: invalid date "2024-13-01", expected 'YYYY-MM-DD'`},
+		{"SELECT sum(food) GROUP BY decade", "1:27: expected day, week, month, quarter or year got \"decade\""},
+		{"SELECT food GROUP BY month", "1:22: GROUP BY requires all selected items to be aggregates"},
+		{"SELECT food\nWHERE food > 1", "2:7: expected a condition on 'on' got \"food\""},
+	} {
+		if _, err := eval(t, test.query); err == nil || err.Error() != test.want {
+			t.Errorf("%q error = %v want %v", test.query, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package timeserie
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Recurrence returns the occurrence times of a schedule in [from, end).
+type Recurrence func(from, end time.Time) []time.Time
+
+// RecurDays returns a recurrence on the days accepted by 'cond', like CondMonthday(1) for a monthly
+// rent or CondLastBusinessDay for a salary.
+func RecurDays(cond TimeCond) Recurrence {
+	return func(from, end time.Time) []time.Time { return Days(from, end, cond) }
+}
+
+// RecurEvery returns a recurrence every 'period' from the start.
+func RecurEvery(period time.Duration) Recurrence {
+	return func(from, end time.Time) []time.Time { return Every(from, end, period) }
+}
+
+// RecurMonths returns a recurrence every 'n' months from the start, on the same day of the month,
+// or on the last day of shorter months.
+func RecurMonths(n int) Recurrence {
+	return func(from, end time.Time) []time.Time {
+		var result []time.Time
+		if n <= 0 {
+			return result
+		}
+		y, m, d := from.Date()
+		for i := 0; ; i += n {
+			last := DayDate(y, m+time.Month(i)+1, 0).Day() // last day of the month.
+			t := DayDate(y, m+time.Month(i), min(d, last))
+			if !t.Before(end) {
+				return result
+			}
+			result = append(result, t)
+		}
+	}
+}
+
+// Amount returns the amount of an occurrence at time 't', NaN for none.
+type Amount func(t time.Time) float64
+
+// AmountFixed returns the same amount 'v' for all occurrences.
+func AmountFixed(v float64) Amount { return func(time.Time) float64 { return v } }
+
+// AmountIndexed returns the amount 'v' at 'base' indexed on 'index', read as a step function: the
+// index rebased to 'v' at 'base', like inflation.Rebase. There is no amount before the first index.
+//
+// An error is returned if there is no index at 'base', or if it is zero.
+func AmountIndexed(v float64, index *Support, base time.Time) (Amount, error) {
+	b, ok := index.Latest(base)
+	if !ok || b == 0 {
+		return nil, fmt.Errorf("no index at base date %s", base.Format(time.DateOnly))
+	}
+	scale := v / b
+	return func(t time.Time) float64 {
+		if x, ok := index.Latest(t); ok {
+			return scale * x
+		}
+		return math.NaN()
+	}, nil
+}
+
+// AmountStepped returns the amount in 'steps' valid at each occurrence: each point of 'steps' sets
+// the amount from its time on. There is no amount before the first step.
+func AmountStepped(steps *Support) Amount {
+	return func(t time.Time) float64 {
+		if v, ok := steps.Latest(t); ok {
+			return v
+		}
+		return math.NaN()
+	}
+}
+
+// Schedule is a recurring amount, like planned payments.
+type Schedule struct {
+	Start, End time.Time // Occurrences are in [Start, End).
+	Recurrence Recurrence
+	Amount     Amount
+}
+
+// Support returns a point per occurrence of the schedule, with its amount. Occurrences without
+// amount are skipped.
+//
+// Planned cash flows are usually compared to actual ones as ModeNullset functions, with Add or Sub.
+func (s Schedule) Support() *Support {
+	res := new(Support)
+	for _, t := range s.Recurrence(s.Start, s.End) {
+		res.Append(t, s.Amount(t))
+	}
+	return res
+}
@@ -0,0 +1,111 @@
+package timeserie_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etnz/timeserie"
+)
+
+// dump returns the value change dump of a single series 'x'.
+func dump(t *testing.T, s *timeserie.Support) string {
+	t.Helper()
+	var b strings.Builder
+	if err := timeserie.Format(&b, map[string]*timeserie.Support{"x": s}); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+// TestSchedule checks recurrences and amount rules.
+func TestSchedule(t *testing.T) {
+	cpi, steps := new(timeserie.Support), new(timeserie.Support)
+	cpi.Append(timeserie.DayDate(2024, 1, 1), 100)
+	cpi.Append(timeserie.DayDate(2025, 1, 1), 103)
+	steps.Append(timeserie.DayDate(2024, 1, 1), 1000)
+	steps.Append(timeserie.DayDate(2024, 3, 1), 1100)
+	q1, q2 := timeserie.DayDate(2024, 1, 1), timeserie.DayDate(2024, 4, 1)
+	indexed, err := timeserie.AmountIndexed(-500, cpi, q1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		schedule timeserie.Schedule
+		want     string
+	}{
+		{"rent", timeserie.Schedule{q1, q2, timeserie.RecurDays(timeserie.CondMonthday(1)), timeserie.AmountFixed(-800)},
+			`{ "on":"24-1-1", "x":-800}
+{ "on":"24-2-1", "x":-800}
+{ "on":"24-3-1", "x":-800}
+`},
+		{"salary", timeserie.Schedule{q1, q2, timeserie.RecurDays(timeserie.CondLastBusinessDay), timeserie.AmountFixed(3000)},
+			`{ "on":"24-1-31", "x":3000}
+{ "on":"24-2-29", "x":3000}
+{ "on":"24-3-29", "x":3000}
+`},
+		{"insurance", timeserie.Schedule{q1, timeserie.DayDate(2026, 1, 1), timeserie.RecurDays(timeserie.CondYearday(time.March, 15)), indexed},
+			`{ "on":"24-3-15", "x":-500}
+{ "on":"25-3-15", "x":-515}
+`},
+		{"stepped", timeserie.Schedule{timeserie.DayDate(2023, 12, 31), q2, timeserie.RecurMonths(1), timeserie.AmountStepped(steps)},
+			`{ "on":"24-1-31", "x":1000}
+{ "on":"24-2-29", "x":1000}
+{ "on":"24-3-31", "x":1100}
+`},
+		{"every", timeserie.Schedule{q1, timeserie.DayDate(2024, 1, 20), timeserie.RecurEvery(7 * timeserie.Day), timeserie.AmountFixed(-50)},
+			`{ "on":"24-1-1", "x":-50}
+{ "on":"24-1-8", "x":-50}
+{ "on":"24-1-15", "x":-50}
+`},
+	} {
+		if got := dump(t, test.schedule.Support()); got != test.want {
+			t.Errorf("%s Support() =\n%s\nwant\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+// TestAmountIndexed checks that a base before the first index is rejected, and that there is no
+// amount before the first index.
+func TestAmountIndexed(t *testing.T) {
+	cpi := new(timeserie.Support)
+	cpi.Append(timeserie.DayDate(2024, 1, 1), 100)
+	cpi.Append(timeserie.DayDate(2025, 1, 1), 103)
+	if _, err := timeserie.AmountIndexed(10, cpi, timeserie.DayDate(2023, 6, 1)); err == nil {
+		t.Errorf("AmountIndexed(base before the first index) want error")
+	}
+	amount, err := timeserie.AmountIndexed(10, cpi, timeserie.DayDate(2025, 6, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := amount(timeserie.DayDate(2023, 6, 1)); !math.IsNaN(v) {
+		t.Errorf("amount before the first index = %v want NaN", v)
+	}
+	if v := amount(timeserie.DayDate(2024, 6, 1)); math.Abs(v-1000.0/103) > 1e-12 {
+		t.Errorf("amount = %v want %v", v, 1000.0/103)
+	}
+}
+
+// TestSchedule_budget compares a planned budget with actual spending.
+func TestSchedule_budget(t *testing.T) {
+	plan := timeserie.Schedule{
+		Start:      timeserie.DayDate(2024, 1, 1),
+		End:        timeserie.DayDate(2024, 3, 1),
+		Recurrence: timeserie.RecurDays(timeserie.CondMonthday(1)),
+		Amount:     timeserie.AmountFixed(-800),
+	}.Support()
+	actual := new(timeserie.Support)
+	actual.Append(timeserie.DayDate(2024, 1, 1), -800)
+	actual.Append(timeserie.DayDate(2024, 2, 1), -850)
+
+	diff := timeserie.Sub(timeserie.New(actual, timeserie.ModeNullset), timeserie.New(plan, timeserie.ModeNullset))
+	want := `{ "on":"24-1-1", "x":0}
+{ "on":"24-2-1", "x":-50}
+`
+	if got := dump(t, &diff.Support); got != want {
+		t.Errorf("actual - plan =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"math"
 	"os"
+	"slices"
 	"strings"
 	"time"
 )
@@ -14,8 +16,34 @@ import (
 const (
 	timeFormat = "06-1-2"
 	attrOn     = "on"
+	attrMeta   = "meta"
 )
 
+// formatTime formats a time of a value change dump: a date in timeFormat, or an RFC 3339 time in
+// UTC if the time is not at the start of a day.
+func formatTime(t time.Time) string {
+	if t.Truncate(Day).Equal(t) {
+		return t.Format(timeFormat)
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// parseTime parses a time formatted by formatTime.
+func parseTime(s string) (time.Time, error) {
+	if on, err := time.Parse(timeFormat, s); err == nil {
+		return on, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// header is a metadata header line of a value change dump: '{"meta": {"id": "food", "unit": "EUR"}}'.
+type header struct {
+	Meta struct {
+		ID string `json:"id"`
+		Meta
+	} `json:"meta"`
+}
+
 // jsonLoad load content from a jsonlist file format.
 func jsonLoad(r io.Reader) (list []any, src []string, err error) {
 	line := 0
@@ -61,6 +89,9 @@ func Open(dict map[string]*Support, filenames ...string) (map[string]*Support, e
 }
 
 // Load support from a value change dump stream.
+//
+// Header lines '{"meta": {"id": "food", "unit": "EUR", ...}}' set the metadata of the series 'id',
+// replacing any previous one.
 func Load(dict map[string]*Support, r io.Reader) error {
 	// Read the source.
 	lines, src, err := jsonLoad(r)
@@ -79,6 +110,22 @@ func Load(dict map[string]*Support, r io.Reader) error {
 			return fmt.Errorf("load support error line %v: json object is required but got %q", i, jline)
 		}
 		jstring, ok := jmap[attrOn]
+		if _, isMeta := jmap[attrMeta]; !ok && isMeta {
+			var h header
+			if err := json.Unmarshal([]byte(jline), &h); err != nil {
+				return fmt.Errorf("load support error line %v: invalid metadata header %q: %w", i, jline, err)
+			}
+			if h.Meta.ID == "" {
+				return fmt.Errorf("load support error line %v: metadata header is missing the attribute 'id': %q", i, jline)
+			}
+			s, ok := dict[h.Meta.ID]
+			if !ok {
+				s = new(Support)
+				dict[h.Meta.ID] = s
+			}
+			s.SetMeta(h.Meta.Meta)
+			continue
+		}
 		if !ok {
 			return fmt.Errorf("load support error line %v: json object is missing the attribute 'on' with a date: %q", i, jline)
 		}
@@ -86,9 +133,9 @@ func Load(dict map[string]*Support, r io.Reader) error {
 			return fmt.Errorf("load support error line %v: attribute 'on' must be of type 'string' got %q", i, jline)
 		}
 
-		on, err := time.Parse(timeFormat, jstring.(string))
+		on, err := parseTime(jstring.(string))
 		if err != nil {
-			return fmt.Errorf("load support error line %v: attribute 'on' must be a valid date in the format %q got %q", i, timeFormat, jstring)
+			return fmt.Errorf("load support error line %v: attribute 'on' must be a valid date in the format %q or an RFC 3339 time got %q", i, timeFormat, jstring)
 		}
 		// Read all other attributes as (key,value) pairs of (Timeserie name, Timeserie value)
 		for id, quantity := range jmap {
@@ -111,15 +158,30 @@ func Load(dict map[string]*Support, r io.Reader) error {
 	return nil
 }
 
+// Format writes supports into a value change dump stream, series are written in alphabetical order.
+//
+// Metadata of the series, if any, is written first as header lines.
 func Format(w io.Writer, dict map[string]*Support) error {
+	ids := slices.Sorted(maps.Keys(dict))
 	var fs []*Function
-	var ids []string
-	for k, v := range dict {
-		fs = append(fs, New(v, ModeNullset))
-		ids = append(ids, k)
+	for _, k := range ids {
+		fs = append(fs, New(dict[k], ModeNullset))
+	}
+	for _, id := range ids {
+		if m := dict[id].Meta(); !m.IsZero() {
+			var h header
+			h.Meta.ID, h.Meta.Meta = id, m
+			data, err := json.Marshal(h)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+				return err
+			}
+		}
 	}
 	for t := range Iterate(fs...) {
-		_, err := fmt.Fprintf(w, "{ %q:%q", attrOn, t.Format(timeFormat))
+		_, err := fmt.Fprintf(w, "{ %q:%q", attrOn, formatTime(t))
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,8 @@
 package timeserie
 
 import (
+	"errors"
+	"fmt"
 	"iter"
 	"math"
 	"slices"
@@ -14,21 +16,24 @@ import (
 type Support struct {
 	times  []time.Time
 	values []float64
+	meta   Meta
 }
 
-// make the support sortable by times.
+// Points in a Support are kept in chronological order. Appending a point at a
+// time already present in the support is allowed: the new point is inserted
+// after the existing ones, so that points sharing the same time keep their
+// insertion order. Use Upsert, Set and Delete to maintain at most one point
+// per time, and Validate to check it.
 
-type sortable struct{ *Support }
-
-func (s sortable) Less(i, j int) bool { return s.times[i].Before(s.times[j]) }
-
-func (s sortable) Swap(i, j int) {
-	s.times[i], s.times[j] = s.times[j], s.times[i]
-	s.values[i], s.values[j] = s.values[j], s.values[i]
+// upper returns the index of the first point strictly after 't'.
+func (s Support) upper(t time.Time) int {
+	return sort.Search(len(s.times), func(i int) bool { return s.times[i].After(t) })
 }
 
-// sort timeserie in chronollogical order.
-func (s *Support) sort() { sort.Sort(sortable{s}) }
+// lower returns the index of the first point at or after 't'.
+func (s Support) lower(t time.Time) int {
+	return sort.Search(len(s.times), func(i int) bool { return !s.times[i].Before(t) })
+}
 
 // Len returns the timeserie support's length.
 func (s Support) Len() int { return len(s.times) }
@@ -37,22 +42,101 @@ func (s Support) Len() int { return len(s.times) }
 func (s Support) At(i int) (time.Time, float64) { return s.times[i], s.values[i] }
 
 // Append a point to this support.
+//
+// NaN values are ignored. If the support already has points at time 'on', the
+// new point is inserted after them.
 func (s *Support) Append(on time.Time, q float64) {
 	if math.IsNaN(q) {
 		return
 	}
-	s.times, s.values = append(s.times, on), append(s.values, q)
-	s.sort()
+	i := s.upper(on)
+	s.times, s.values = slices.Insert(s.times, i, on), slices.Insert(s.values, i, q)
+}
+
+// Upsert sets the value at time 'on', replacing any existing points at that
+// time by a single one. It returns true if a point was replaced.
+//
+// A NaN value deletes the points at time 'on'.
+func (s *Support) Upsert(on time.Time, q float64) bool {
+	replaced := s.Delete(on) > 0
+	s.Append(on, q)
+	return replaced
+}
+
+// Set replaces the value at time 'on' if the support has a point at that time,
+// it returns false otherwise and the support is left unchanged.
+//
+// Duplicated points at time 'on' are replaced by a single one.
+func (s *Support) Set(on time.Time, q float64) bool {
+	if i := s.lower(on); i == s.Len() || !s.times[i].Equal(on) {
+		return false
+	}
+	return s.Upsert(on, q)
+}
+
+// Delete removes all points at time 'on' and returns the number of removed points.
+func (s *Support) Delete(on time.Time) int {
+	i, j := s.lower(on), s.upper(on)
+	s.times, s.values = slices.Delete(s.times, i, j), slices.Delete(s.values, i, j)
+	return j - i
+}
+
+// Validate checks that the support is in chronological order and that it has
+// at most one point per time. It returns an error listing all violations, or
+// nil.
+func (s Support) Validate() error {
+	var errs []error
+	for i := 1; i < len(s.times); i++ {
+		switch prev, on := s.times[i-1], s.times[i]; {
+		case on.Before(prev):
+			errs = append(errs, fmt.Errorf("point %v at %v is before point %v at %v", i, on, i-1, prev))
+		case on.Equal(prev):
+			errs = append(errs, fmt.Errorf("point %v at %v is a duplicate of point %v", i, on, i-1))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Unique returns a new support with at most one point per time, keeping the
+// last appended value.
+func (s *Support) Unique() *Support {
+	res := &Support{meta: s.meta.Clone()}
+	for i, on := range s.times {
+		if i+1 < len(s.times) && s.times[i+1].Equal(on) {
+			continue
+		}
+		res.Append(on, s.values[i])
+	}
+	return res
+}
 
+// Clone returns a copy of this support.
+func (s *Support) Clone() *Support {
+	return &Support{times: slices.Clone(s.times), values: slices.Clone(s.values), meta: s.meta.Clone()}
+}
+
+// Slice returns a new support with the points in [from, to).
+func (s *Support) Slice(from, to time.Time) *Support {
+	i, j := s.lower(from), s.lower(to)
+	if j < i {
+		j = i
+	}
+	return &Support{times: slices.Clone(s.times[i:j]), values: slices.Clone(s.values[i:j]), meta: s.meta.Clone()}
 }
 
 // Find returns the index of the closest value before 't'.
-func (s Support) Find(t time.Time) int {
-	next := slices.IndexFunc(s.times, func(i time.Time) bool { return i.After(t) })
-	if next < 0 {
-		return s.Len() - 1
+//
+// If the support has several points at the closest time, the last appended
+// one is returned.
+func (s Support) Find(t time.Time) int { return s.upper(t) - 1 }
+
+// Latest returns the value of the closest point at or before 't', and false if there is none.
+func (s Support) Latest(t time.Time) (float64, bool) {
+	i := s.Find(t)
+	if i < 0 {
+		return 0, false
 	}
-	return next - 1
+	return s.values[i], true
 }
 
 // Values return an iterator over all values in the support.
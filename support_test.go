@@ -89,6 +89,23 @@ func TestSupport_Find(t *testing.T) {
 	}
 }
 
+// TestSupport_Latest checks the value at or before a time.
+func TestSupport_Latest(t *testing.T) {
+	s := new(timeserie.Support)
+	s.Append(d0, 1.0)
+	s.Append(d1, 2.0)
+
+	if _, ok := s.Latest(d0.Add(-time.Hour)); ok {
+		t.Errorf("Latest(before d0) ok = true want false")
+	}
+	if v, ok := s.Latest(d1); !ok || v != 2 {
+		t.Errorf("Latest(d1) = %v, %v want 2, true", v, ok)
+	}
+	if v, ok := s.Latest(d2); !ok || v != 2 {
+		t.Errorf("Latest(d2) = %v, %v want 2, true", v, ok)
+	}
+}
+
 // TestSupport_Values tries a simple case.
 func TestSupport_Values(t *testing.T) {
 	low := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -184,3 +201,132 @@ func TestSupport_Scan(t *testing.T) {
 		t.Errorf("{1,2}.Scan(Acc)[1] =%v,%v want %v, %v", x1, v1, d1, 3.0)
 	}
 }
+
+// TestSupport_Append_stable checks that points at the same time keep their insertion order.
+func TestSupport_Append_stable(t *testing.T) {
+	d0, d1 := timeserie.DayDate(2000, 1, 1), timeserie.DayDate(2000, 1, 2)
+	s := new(timeserie.Support)
+	s.Append(d1, 1.0)
+	s.Append(d0, 2.0)
+	s.Append(d1, 3.0)
+	s.Append(d1, 4.0)
+
+	want := []float64{2, 1, 3, 4}
+	for i, w := range want {
+		if _, v := s.At(i); v != w {
+			t.Errorf("At(%v) = %v want %v", i, v, w)
+		}
+	}
+	// Find must return the last appended value
+	if x := s.Find(d1); x != 3 {
+		t.Errorf("Find(d1) = %v want 3", x)
+	}
+}
+
+// TestSupport_Upsert checks that Upsert replaces duplicates.
+func TestSupport_Upsert(t *testing.T) {
+	d0, d1 := timeserie.DayDate(2000, 1, 1), timeserie.DayDate(2000, 1, 2)
+	s := new(timeserie.Support)
+	s.Append(d0, 1.0)
+	s.Append(d1, 2.0)
+	s.Append(d1, 3.0)
+
+	if s.Upsert(d1, 4.0) != true {
+		t.Errorf("Upsert(d1) = false want true")
+	}
+	if s.Len() != 2 {
+		t.Errorf("Upsert(d1).Len() = %v want 2", s.Len())
+	}
+	if on, v := s.At(1); on != d1 || v != 4.0 {
+		t.Errorf("Upsert(d1).At(1) = %v,%v want %v,%v", on, v, d1, 4.0)
+	}
+
+	d2 := timeserie.DayDate(2000, 1, 3)
+	if s.Upsert(d2, 5.0) != false {
+		t.Errorf("Upsert(d2) = true want false")
+	}
+	if s.Len() != 3 {
+		t.Errorf("Upsert(d2).Len() = %v want 3", s.Len())
+	}
+}
+
+// TestSupport_Set checks that Set only replaces existing points.
+func TestSupport_Set(t *testing.T) {
+	d0, d1 := timeserie.DayDate(2000, 1, 1), timeserie.DayDate(2000, 1, 2)
+	s := new(timeserie.Support)
+	s.Append(d0, 1.0)
+
+	if s.Set(d1, 2.0) {
+		t.Errorf("Set(d1) = true want false")
+	}
+	if s.Len() != 1 {
+		t.Errorf("Set(d1).Len() = %v want 1", s.Len())
+	}
+	if !s.Set(d0, 3.0) {
+		t.Errorf("Set(d0) = false want true")
+	}
+	if _, v := s.At(0); v != 3.0 {
+		t.Errorf("Set(d0).At(0) = %v want 3", v)
+	}
+}
+
+// TestSupport_Delete checks that all points at a time are removed.
+func TestSupport_Delete(t *testing.T) {
+	d0, d1 := timeserie.DayDate(2000, 1, 1), timeserie.DayDate(2000, 1, 2)
+	s := new(timeserie.Support)
+	s.Append(d0, 1.0)
+	s.Append(d1, 2.0)
+	s.Append(d1, 3.0)
+
+	if n := s.Delete(d1); n != 2 {
+		t.Errorf("Delete(d1) = %v want 2", n)
+	}
+	if n := s.Delete(d1); n != 0 {
+		t.Errorf("Delete(d1) again = %v want 0", n)
+	}
+	if s.Len() != 1 {
+		t.Errorf("Delete(d1).Len() = %v want 1", s.Len())
+	}
+}
+
+// TestSupport_Validate checks that duplicates are reported.
+func TestSupport_Validate(t *testing.T) {
+	d0, d1 := timeserie.DayDate(2000, 1, 1), timeserie.DayDate(2000, 1, 2)
+	s := new(timeserie.Support)
+	s.Append(d0, 1.0)
+	s.Append(d1, 2.0)
+	if err := s.Validate(); err != nil {
+		t.Errorf("Validate() = %v want nil", err)
+	}
+	s.Append(d1, 3.0)
+	if err := s.Validate(); err == nil {
+		t.Errorf("Validate() = nil want duplicate error")
+	}
+	u := s.Unique()
+	if err := u.Validate(); err != nil {
+		t.Errorf("Unique().Validate() = %v want nil", err)
+	}
+	if _, v := u.At(1); v != 3.0 {
+		t.Errorf("Unique().At(1) = %v want 3", v)
+	}
+}
+
+// TestSupport_Slice checks bounds inclusion.
+func TestSupport_Slice(t *testing.T) {
+	d0, d1, d2 := timeserie.DayDate(2000, 1, 1), timeserie.DayDate(2000, 1, 2), timeserie.DayDate(2000, 1, 3)
+	s := new(timeserie.Support)
+	s.Append(d0, 1.0)
+	s.Append(d1, 2.0)
+	s.Append(d2, 3.0)
+
+	x := s.Slice(d1, d2)
+	if x.Len() != 1 {
+		t.Errorf("Slice(d1, d2).Len() = %v want 1", x.Len())
+	}
+	if on, v := x.At(0); on != d1 || v != 2.0 {
+		t.Errorf("Slice(d1, d2).At(0) = %v, %v want %v, 2", on, v, d1)
+	}
+	if x := s.Slice(d2, d0); x.Len() != 0 {
+		t.Errorf("Slice(d2, d0).Len() = %v want 0", x.Len())
+	}
+}
@@ -34,6 +34,41 @@ func CondQuarterly(t time.Time) bool { _, m, d := t.Date(); return d == 1 && m%3
 // Condyearly returns true if 't' represent the first day of a new year (a january first).
 func CondYearly(t time.Time) bool { _, m, d := t.Date(); return d == 1 && m == 1 }
 
+// CondDaily returns true for any day.
+func CondDaily(t time.Time) bool { return true }
+
+// CondYearday returns a condition true on the given day of the year, like March 15th.
+func CondYearday(month time.Month, day int) TimeCond {
+	return func(t time.Time) bool { _, m, d := t.Date(); return m == month && d == day }
+}
+
+// CondBusinessDay returns true if 't' is a day from Monday to Friday.
+func CondBusinessDay(t time.Time) bool {
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+// CondLastBusinessDay returns true if 't' is the last business day of the month.
+func CondLastBusinessDay(t time.Time) bool {
+	if !CondBusinessDay(t) {
+		return false
+	}
+	for d := t.Add(Day); d.Month() == t.Month(); d = d.Add(Day) {
+		if CondBusinessDay(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// Periods are named calendar periods, as the condition on their first day.
+var Periods = map[string]TimeCond{
+	"day":     CondDaily,
+	"week":    CondWeekday(time.Monday),
+	"month":   CondMonthday(1),
+	"quarter": CondQuarterly,
+	"year":    CondYearly,
+}
+
 // Days returns a list of all days starting with 'from' (included) ends after 'end' and return only
 // days accepted by time condition.
 func Days(from, end time.Time, accept TimeCond) []time.Time {
@@ -47,6 +82,19 @@ func Days(from, end time.Time, accept TimeCond) []time.Time {
 	return result
 }
 
+// Every returns a list of times starting with 'from' (included), separated by 'period', and
+// ending before 'end'.
+func Every(from, end time.Time, period time.Duration) []time.Time {
+	var result []time.Time
+	if period <= 0 {
+		return result
+	}
+	for t := from; t.Before(end); t = t.Add(period) {
+		result = append(result, t)
+	}
+	return result
+}
+
 // Scanner is a function that can be used in the Scan method.
 type Scanner func(c, s float64) float64
 
@@ -0,0 +1,158 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/etnz/timeserie"
+)
+
+// Of returns the unit of a function, and false if it has none.
+func Of(f *timeserie.Function) (Unit, bool, error) {
+	name := f.Meta().Unit
+	if name == "" {
+		return Unit{}, false, nil
+	}
+	u, err := Parse(name)
+	return u, err == nil, err
+}
+
+// With returns 'f' with the unit 'u'. The unit is "" for unknown.
+func With(f *timeserie.Function, unit string) *timeserie.Function {
+	m := f.Meta().Clone()
+	m.Unit = unit
+	res := timeserie.New(&f.Support, f.Mode())
+	res.SetMeta(m)
+	return res
+}
+
+// scale returns 'f' with values multiplied by 'k'.
+func scale(f *timeserie.Function, k float64) *timeserie.Function {
+	if k == 1 {
+		return f
+	}
+	s := new(timeserie.Support)
+	for t, v := range f.Values() {
+		s.Append(t, v*k)
+	}
+	s.SetMeta(f.Meta())
+	return timeserie.New(s, f.Mode())
+}
+
+// Convert returns 'f' converted to 'unit'.
+func Convert(f *timeserie.Function, unit string) (*timeserie.Function, error) {
+	to, err := Parse(unit)
+	if err != nil {
+		return nil, err
+	}
+	from, ok, err := Of(f)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("cannot convert to %q: the series has no unit", unit)
+	}
+	k, err := from.Factor(to)
+	if err != nil {
+		return nil, err
+	}
+	return With(scale(f, k), to.String()), nil
+}
+
+// ErrNoUnit is returned by arithmetic on a series without unit.
+var ErrNoUnit = errors.New("series without unit")
+
+// units returns the units of 'fs', or an error wrapping ErrNoUnit if any of them has no unit.
+func units(op string, fs []*timeserie.Function) ([]Unit, error) {
+	us := make([]Unit, len(fs))
+	for i, f := range fs {
+		u, ok, err := Of(f)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("cannot %s: %w, set a unit like \"1\" or use timeserie.%s", op, ErrNoUnit, functions[op])
+		}
+		us[i] = u
+	}
+	return us, nil
+}
+
+// functions are the timeserie functions of operations.
+var functions = map[string]string{"add": "Add", "subtract": "Sub", "multiply": "Times", "divide": "Div"}
+
+// Add returns the sum of functions, like timeserie.Add, converted to the unit of the first one.
+//
+// It is an error to add functions of different dimensions, or without unit: functions without unit
+// are added with timeserie.Add.
+func Add(fs ...*timeserie.Function) (*timeserie.Function, error) {
+	us, err := units("add", fs)
+	if err != nil {
+		return nil, err
+	}
+	if len(fs) == 0 {
+		return timeserie.Add(fs...), nil
+	}
+	fs, err = align("add", us, fs)
+	if err != nil {
+		return nil, err
+	}
+	return With(timeserie.Add(fs...), us[0].String()), nil
+}
+
+// Sub returns a-b, like timeserie.Sub, in the unit of 'a'.
+//
+// It is an error to subtract functions of different dimensions, or without unit.
+func Sub(a, b *timeserie.Function) (*timeserie.Function, error) {
+	us, err := units("subtract", []*timeserie.Function{a, b})
+	if err != nil {
+		return nil, err
+	}
+	fs, err := align("subtract", us, []*timeserie.Function{a, b})
+	if err != nil {
+		return nil, err
+	}
+	return With(timeserie.Sub(fs[0], fs[1]), us[0].String()), nil
+}
+
+// align converts all functions to the unit of the first one.
+func align(op string, us []Unit, fs []*timeserie.Function) ([]*timeserie.Function, error) {
+	res := []*timeserie.Function{fs[0]}
+	for i, f := range fs[1:] {
+		k, err := us[i+1].Factor(us[0])
+		if err != nil {
+			return nil, fmt.Errorf("cannot %s %q and %q: incompatible dimensions", op, us[0], us[i+1])
+		}
+		res = append(res, scale(f, k))
+	}
+	return res, nil
+}
+
+// Times returns the product of functions, like timeserie.Times, with the product of their units.
+//
+// It is an error to multiply functions without unit.
+func Times(fs ...*timeserie.Function) (*timeserie.Function, error) {
+	us, err := units("multiply", fs)
+	if err != nil {
+		return nil, err
+	}
+	if len(fs) == 0 {
+		return timeserie.Times(fs...), nil
+	}
+	u := us[0]
+	for _, v := range us[1:] {
+		u = u.Mul(v)
+	}
+	return With(timeserie.Times(fs...), u.String()), nil
+}
+
+// Div returns a/b, like timeserie.Div, with the unit a/b.
+//
+// It is an error to divide functions without unit.
+func Div(a, b *timeserie.Function) (*timeserie.Function, error) {
+	us, err := units("divide", []*timeserie.Function{a, b})
+	if err != nil {
+		return nil, err
+	}
+	return With(timeserie.Div(a, b), us[0].Div(us[1]).String()), nil
+}
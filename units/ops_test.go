@@ -0,0 +1,104 @@
+package units_test
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/etnz/timeserie"
+	"github.com/etnz/timeserie/units"
+)
+
+var (
+	d0 = timeserie.DayDate(2024, 1, 1)
+	d1 = timeserie.DayDate(2024, 1, 2)
+)
+
+// fn returns a step function with 'unit' and values at d0 and d1.
+func fn(unit string, v0, v1 float64) *timeserie.Function {
+	s := new(timeserie.Support)
+	s.Append(d0, v0)
+	s.Append(d1, v1)
+	s.SetMeta(timeserie.Meta{Unit: unit})
+	return timeserie.New(s, timeserie.ModeStep)
+}
+
+// values returns the values of 'f'.
+func values(f *timeserie.Function) []float64 {
+	var vs []float64
+	for _, v := range f.Values() {
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+func TestOps(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		op     func() (*timeserie.Function, error)
+		unit   string
+		values []float64
+	}{
+		{"kWh+MWh", func() (*timeserie.Function, error) { return units.Add(fn("kWh", 1, 2), fn("MWh", 1, 0.5)) }, "kWh", []float64{1001, 502}},
+		{"MWh-kWh", func() (*timeserie.Function, error) { return units.Sub(fn("MWh", 1, 2), fn("kWh", 500, 1000)) }, "MWh", []float64{0.5, 1}},
+		{"kW*h", func() (*timeserie.Function, error) { return units.Times(fn("kW", 2, 3), fn("h", 24, 24)) }, "kWh", []float64{48, 72}},
+		{"EUR/kWh", func() (*timeserie.Function, error) { return units.Div(fn("EUR", 10, 30), fn("kWh", 50, 100)) }, "EUR/kWh", []float64{0.2, 0.3}},
+		{"convert", func() (*timeserie.Function, error) { return units.Convert(fn("kWh", 1500, 250), "MWh") }, "MWh", []float64{1.5, 0.25}},
+	} {
+		f, err := test.op()
+		if err != nil {
+			t.Errorf("%s error: %v", test.name, err)
+			continue
+		}
+		if got := f.Meta().Unit; got != test.unit {
+			t.Errorf("%s unit = %q, want %q", test.name, got, test.unit)
+		}
+		if got := values(f); !slices.EqualFunc(got, test.values, func(a, b float64) bool { return a-b < 1e-9 && b-a < 1e-9 }) {
+			t.Errorf("%s = %v, want %v", test.name, got, test.values)
+		}
+	}
+}
+
+func TestOps_errors(t *testing.T) {
+	if _, err := units.Add(fn("kWh", 1, 2), fn("EUR", 1, 2)); err == nil || err.Error() != `cannot add "kWh" and "EUR": incompatible dimensions` {
+		t.Errorf("Add(kWh, EUR) error = %v", err)
+	}
+	if _, err := units.Sub(fn("EUR", 1, 2), fn("USD", 1, 2)); err == nil {
+		t.Error("Sub(EUR, USD) succeeded, want an error")
+	}
+	if _, err := units.Convert(fn("kWh", 1, 2), "EUR"); err == nil {
+		t.Error("Convert(kWh, EUR) succeeded, want an error")
+	}
+	if _, err := units.Convert(fn("", 1, 2), "EUR"); err == nil {
+		t.Error("Convert without unit succeeded, want an error")
+	}
+	if _, err := units.Add(fn("kWx", 1, 2), fn("kWh", 1, 2)); err == nil {
+		t.Error("Add with invalid unit succeeded, want an error")
+	}
+	for name, err := range map[string]error{
+		"Add":   second(units.Add(fn("kWh", 1, 2), fn("", 1, 1))),
+		"Sub":   second(units.Sub(fn("", 1, 2), fn("", 1, 1))),
+		"Times": second(units.Times(fn("kW", 1, 2), fn("", 1, 1))),
+		"Div":   second(units.Div(fn("", 1, 2), fn("h", 1, 1))),
+	} {
+		if !errors.Is(err, units.ErrNoUnit) {
+			t.Errorf("%s without unit error = %v, want ErrNoUnit", name, err)
+		}
+	}
+}
+
+// second returns the error of an operation.
+func second(_ *timeserie.Function, err error) error { return err }
+
+func TestWith(t *testing.T) {
+	f := fn("kWh", 1, 2)
+	f.Support.SetMeta(timeserie.Meta{Name: "Power", Unit: "kWh"})
+	g := units.With(f, "MWh")
+	if want := (timeserie.Meta{Name: "Power", Unit: "MWh"}); !reflect.DeepEqual(g.Meta(), want) {
+		t.Errorf("With() meta = %+v, want %+v", g.Meta(), want)
+	}
+	if f.Meta().Unit != "kWh" {
+		t.Errorf("With() changed the original unit to %q", f.Meta().Unit)
+	}
+}
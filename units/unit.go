@@ -0,0 +1,383 @@
+// Package units checks and converts the units of series.
+//
+// Units are read from and written to the series metadata (see timeserie.Meta). They are products of
+// symbols with optional exponents, like "kWh", "EUR/MWh", "m/s^2" or "kg*m2". Symbols are SI base
+// units, common derived units (W, Wh, J, N, Pa, h, L, ...), optionally with a prefix (T, G, M, k,
+// d, c, m, u or µ, n, p), ISO 4217 currency codes, like "EUR", and units registered with Define.
+//
+// Currencies are dimensions of their own: amounts in different currencies cannot be added without
+// exchange rates. Other currencies, like "BTC", are registered as new dimensions with Define. Units
+// with an offset, like degree Celsius, are not supported.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Unit is a physical unit: a scale relative to the coherent SI unit of its dimension.
+type Unit struct {
+	name  string
+	scale float64
+	dims  map[string]int // exponents by base dimension, like "m", "kg", "s" or "EUR".
+}
+
+// String returns the unit symbol.
+func (u Unit) String() string { return u.name }
+
+// Dimensionless reports whether the unit has no dimension, like "1" or "%".
+func (u Unit) Dimensionless() bool { return len(u.dims) == 0 }
+
+// Compatible reports whether 'u' and 'v' have the same dimension.
+func (u Unit) Compatible(v Unit) bool { return maps.Equal(u.dims, v.dims) }
+
+// Factor returns the factor to convert values in unit 'u' into unit 'to'.
+func (u Unit) Factor(to Unit) (float64, error) {
+	if !u.Compatible(to) {
+		return 0, fmt.Errorf("cannot convert %q to %q: incompatible dimensions", u, to)
+	}
+	return u.scale / to.scale, nil
+}
+
+// Mul returns the unit u*v.
+func (u Unit) Mul(v Unit) Unit {
+	dims := maps.Clone(u.dims)
+	for d, e := range v.dims {
+		dims[d] += e
+	}
+	return named(compose(u.name, "*", v.name), u.scale*v.scale, dims)
+}
+
+// Div returns the unit u/v.
+func (u Unit) Div(v Unit) Unit {
+	dims := maps.Clone(u.dims)
+	for d, e := range v.dims {
+		dims[d] -= e
+	}
+	return named(compose(u.name, "/", v.name), u.scale/v.scale, dims)
+}
+
+// pow returns the unit u^n.
+func (u Unit) pow(n int) Unit {
+	dims := make(map[string]int)
+	for d, e := range u.dims {
+		dims[d] = e * n
+	}
+	return Unit{u.name + "^" + strconv.Itoa(n), math.Pow(u.scale, float64(n)), dims}
+}
+
+// compose returns the name of 'a' 'op' 'b', with parentheses if needed.
+func compose(a, op, b string) string {
+	if op == "/" && strings.ContainsAny(b, "*/") {
+		b = "(" + b + ")"
+	}
+	return a + op + b
+}
+
+// named returns the unit with 'scale' and 'dims', named after a known unit when there is one, or
+// 'name' otherwise.
+func named(name string, scale float64, dims map[string]int) Unit {
+	for d, e := range dims {
+		if e == 0 {
+			delete(dims, d)
+		}
+	}
+	u := Unit{name, scale, dims}
+	if len(dims) == 0 && scale == 1 {
+		u.name = "1"
+		return u
+	}
+	if len(dims) == 1 {
+		for d, e := range dims {
+			if e != 1 || !currency(d) {
+				break
+			}
+			if scale == 1 {
+				u.name = d
+				return u
+			}
+			for _, p := range prefixes {
+				if equal(p.factor, scale) {
+					u.name = p.symbol + d
+					return u
+				}
+			}
+		}
+	}
+	var best string
+	for _, symbol := range slices.Sorted(maps.Keys(registry)) {
+		r := registry[symbol]
+		if len(r.dims) == 0 || !maps.Equal(r.dims, dims) {
+			continue
+		}
+		if equal(r.scale, scale) {
+			best = shorter(best, symbol)
+		}
+		if !r.prefixable {
+			continue
+		}
+		for _, p := range prefixes {
+			if equal(r.scale*p.factor, scale) {
+				best = shorter(best, p.symbol+symbol)
+			}
+		}
+	}
+	if best != "" {
+		u.name = best
+	}
+	return u
+}
+
+// shorter returns the shortest of 'a' and 'b', 'b' if 'a' is empty.
+func shorter(a, b string) string {
+	if a == "" || len(b) < len(a) {
+		return b
+	}
+	return a
+}
+
+// equal reports whether scales are equal up to rounding errors.
+func equal(a, b float64) bool { return math.Abs(a-b) <= 1e-12*math.Max(math.Abs(a), math.Abs(b)) }
+
+// entry is a registered unit symbol.
+type entry struct {
+	Unit
+	prefixable bool // whether the symbol accepts prefixes.
+}
+
+// registry holds the registered unit symbols.
+var registry = make(map[string]entry)
+
+// prefixes are the accepted SI prefixes.
+var prefixes = []struct {
+	symbol string
+	factor float64
+}{
+	{"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"k", 1e3}, {"d", 1e-1}, {"c", 1e-2},
+	{"m", 1e-3}, {"u", 1e-6}, {"µ", 1e-6}, {"n", 1e-9}, {"p", 1e-12},
+}
+
+func init() {
+	for _, base := range []string{"m", "s", "A", "K", "mol", "cd"} {
+		registry[base] = entry{Unit{base, 1, map[string]int{base: 1}}, true}
+	}
+	registry["g"] = entry{Unit{"g", 1e-3, map[string]int{"kg": 1}}, true}
+	for _, d := range []struct {
+		symbol     string
+		factor     float64
+		unit       string
+		prefixable bool
+	}{
+		{"1", 1, "", false},
+		{"%", 0.01, "1", false},
+		{"min", 60, "s", false},
+		{"h", 3600, "s", false},
+		{"d", 86400, "s", false},
+		{"t", 1000, "kg", true},
+		{"L", 1e-3, "m^3", true},
+		{"Hz", 1, "1/s", true},
+		{"N", 1, "kg*m/s^2", true},
+		{"Pa", 1, "N/m^2", true},
+		{"bar", 1e5, "Pa", true},
+		{"J", 1, "N*m", true},
+		{"W", 1, "J/s", true},
+		{"Wh", 3600, "J", true},
+		{"C", 1, "A*s", true},
+		{"V", 1, "W/A", true},
+		{"Ω", 1, "V/A", true},
+	} {
+		if d.symbol == "1" {
+			registry["1"] = entry{Unit{"1", 1, map[string]int{}}, false}
+			continue
+		}
+		if err := define(d.symbol, d.factor, d.unit, d.prefixable); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Define registers the unit 'symbol' as 'factor' times 'unit', or as a new base dimension if 'unit'
+// is empty. Defined symbols accept prefixes.
+//
+// Define is not safe for concurrent use, it is meant to be called during initialization.
+func Define(symbol string, factor float64, unit string) error {
+	return define(symbol, factor, unit, true)
+}
+
+func define(symbol string, factor float64, unit string, prefixable bool) error {
+	if symbol == "" || strings.IndexFunc(symbol, func(r rune) bool { return !isSymbol(r) }) >= 0 {
+		return fmt.Errorf("invalid unit symbol %q", symbol)
+	}
+	if _, ok := registry[symbol]; ok {
+		return fmt.Errorf("unit %q is already defined", symbol)
+	}
+	if unit == "" {
+		registry[symbol] = entry{Unit{symbol, factor, map[string]int{symbol: 1}}, prefixable}
+		return nil
+	}
+	u, err := Parse(unit)
+	if err != nil {
+		return err
+	}
+	registry[symbol] = entry{Unit{symbol, factor * u.scale, u.dims}, prefixable}
+	return nil
+}
+
+// currency reports whether 'symbol' is an ISO 4217 currency code.
+func currency(symbol string) bool { return iso4217[symbol] }
+
+// iso4217 is the set of ISO 4217 currency codes, including funds and precious metals.
+var iso4217 = func() map[string]bool {
+	codes := make(map[string]bool)
+	for _, code := range strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BOV BRL BSD
+		BTN BWP BYN BZD CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUC CUP CVE CZK DJF DKK DOP
+		DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR
+		IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD
+		MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB
+		PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SLL SOS SRD
+		SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD USN UYI UYU UYW UZS
+		VED VES VND VUV WST XAF XAG XAU XBA XBB XBC XBD XCD XCG XDR XOF XPD XPF XPT XSU XUA YER
+		ZAR ZMW ZWG ZWL`) {
+		codes[code] = true
+	}
+	return codes
+}()
+
+// lookup returns the unit of a symbol, possibly prefixed.
+func lookup(symbol string) (Unit, bool) {
+	if e, ok := registry[symbol]; ok {
+		return e.Unit, true
+	}
+	if currency(symbol) {
+		return Unit{symbol, 1, map[string]int{symbol: 1}}, true
+	}
+	for _, p := range prefixes {
+		rest, ok := strings.CutPrefix(symbol, p.symbol)
+		if !ok || rest == "" {
+			continue
+		}
+		if e, ok := registry[rest]; ok && e.prefixable {
+			return Unit{symbol, p.factor * e.scale, e.dims}, true
+		}
+		if currency(rest) {
+			return Unit{symbol, p.factor, map[string]int{rest: 1}}, true
+		}
+	}
+	return Unit{}, false
+}
+
+// isSymbol reports whether 'r' can be part of a unit symbol.
+func isSymbol(r rune) bool { return unicode.IsLetter(r) || r == '%' }
+
+// Parse parses a unit.
+func Parse(s string) (Unit, error) {
+	p := &parser{src: strings.Join(strings.Fields(s), "")}
+	if p.src == "" {
+		return Unit{}, errors.New("empty unit")
+	}
+	u, err := p.product()
+	if err == nil && p.i < len(p.src) {
+		err = fmt.Errorf("unexpected %q", p.src[p.i:])
+	}
+	if err != nil {
+		return Unit{}, fmt.Errorf("invalid unit %q: %w", s, err)
+	}
+	u.name = p.src
+	return u, nil
+}
+
+// parser parses units.
+type parser struct {
+	src string
+	i   int
+}
+
+// product parses factors separated by '*', '.' or '/'.
+func (p *parser) product() (Unit, error) {
+	u, err := p.factor()
+	if err != nil {
+		return u, err
+	}
+	for p.i < len(p.src) {
+		op := p.src[p.i]
+		if op != '*' && op != '.' && op != '/' {
+			break
+		}
+		p.i++
+		v, err := p.factor()
+		if err != nil {
+			return u, err
+		}
+		if op == '/' {
+			u = u.Div(v)
+		} else {
+			u = u.Mul(v)
+		}
+	}
+	return u, nil
+}
+
+// factor parses a symbol or a parenthesized product, with an optional exponent.
+func (p *parser) factor() (Unit, error) {
+	var u Unit
+	switch {
+	case p.i < len(p.src) && p.src[p.i] == '(':
+		p.i++
+		var err error
+		if u, err = p.product(); err != nil {
+			return u, err
+		}
+		if p.i >= len(p.src) || p.src[p.i] != ')' {
+			return u, errors.New("missing ')'")
+		}
+		p.i++
+	case p.i < len(p.src) && p.src[p.i] == '1':
+		p.i++
+		u = registry["1"].Unit
+	default:
+		start := p.i
+		for p.i < len(p.src) {
+			r, n := utf8.DecodeRuneInString(p.src[p.i:])
+			if !isSymbol(r) {
+				break
+			}
+			p.i += n
+		}
+		symbol := p.src[start:p.i]
+		if symbol == "" {
+			return u, errors.New("missing unit symbol")
+		}
+		var ok bool
+		if u, ok = lookup(symbol); !ok {
+			return u, fmt.Errorf("unknown unit %q", symbol)
+		}
+	}
+	// Optional exponent: '^2', '^-1' or '2'.
+	start := p.i
+	if p.i < len(p.src) && p.src[p.i] == '^' {
+		p.i++
+		if p.i < len(p.src) && p.src[p.i] == '-' {
+			p.i++
+		}
+	}
+	for p.i < len(p.src) && '0' <= p.src[p.i] && p.src[p.i] <= '9' {
+		p.i++
+	}
+	if exp := p.src[start:p.i]; exp != "" {
+		exp = strings.TrimPrefix(exp, "^")
+		n, err := strconv.Atoi(exp)
+		if err != nil {
+			return u, fmt.Errorf("invalid exponent %q", exp)
+		}
+		u = u.pow(n)
+	}
+	return u, nil
+}
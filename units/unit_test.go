@@ -0,0 +1,123 @@
+package units_test
+
+import (
+	"testing"
+
+	"github.com/etnz/timeserie/units"
+)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		from, to string
+		factor   float64
+	}{
+		{"kWh", "MWh", 0.001},
+		{"kWh", "J", 3.6e6},
+		{"kW*h", "kWh", 1},
+		{"W.s", "J", 1},
+		{"km/h", "m/s", 1 / 3.6},
+		{"m/s^2", "N/kg", 1},
+		{"cm2", "m^2", 1e-4},
+		{"L", "dm3", 1},
+		{"kEUR", "EUR", 1000},
+		{"EUR/MWh", "EUR/kWh", 0.001},
+		{"%", "1", 0.01},
+		{"1/min", "Hz", 1.0 / 60},
+		{"µs", "us", 1},
+		{"kg*m2/(s3*A)", "V", 1},
+	} {
+		from, err := units.Parse(test.from)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", test.from, err)
+			continue
+		}
+		to, err := units.Parse(test.to)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", test.to, err)
+			continue
+		}
+		k, err := from.Factor(to)
+		if err != nil {
+			t.Errorf("%q.Factor(%q) error: %v", test.from, test.to, err)
+			continue
+		}
+		if d := k/test.factor - 1; d > 1e-12 || d < -1e-12 {
+			t.Errorf("%q.Factor(%q) = %v, want %v", test.from, test.to, k, test.factor)
+		}
+	}
+
+	for _, src := range []string{"", "kWx", "m^", "(m", "m/", "kh", "EURO", "m)", "KWH", "ABC"} {
+		if _, err := units.Parse(src); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestUnit_Factor(t *testing.T) {
+	for _, test := range [][2]string{{"kWh", "EUR"}, {"EUR", "USD"}, {"m", "s"}, {"kW", "kWh"}} {
+		a, _ := units.Parse(test[0])
+		b, _ := units.Parse(test[1])
+		if a.Compatible(b) {
+			t.Errorf("%q.Compatible(%q) = true", test[0], test[1])
+		}
+		if _, err := a.Factor(b); err == nil {
+			t.Errorf("%q.Factor(%q) succeeded, want an error", test[0], test[1])
+		}
+	}
+}
+
+func TestUnit_Mul(t *testing.T) {
+	parse := func(s string) units.Unit {
+		u, err := units.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	for _, test := range []struct {
+		got  units.Unit
+		want string
+	}{
+		{parse("kW").Mul(parse("h")), "kWh"},
+		{parse("W").Mul(parse("s")), "J"},
+		{parse("kWh").Div(parse("h")), "kW"},
+		{parse("EUR/MWh").Mul(parse("MWh")), "EUR"},
+		{parse("EUR").Div(parse("MWh")), "EUR/MWh"},
+		{parse("kWh").Mul(parse("EUR/MWh")), "mEUR"},
+		{parse("m").Div(parse("m")), "1"},
+		{parse("EUR").Div(parse("kW*h")), "EUR/(kW*h)"},
+		{parse("EUR").Div(parse("m*m")), "EUR/(m*m)"},
+	} {
+		if got := test.got.String(); got != test.want {
+			t.Errorf("unit = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestDefine(t *testing.T) {
+	if err := units.Define("cal", 4.184, "J"); err != nil {
+		t.Fatal(err)
+	}
+	if err := units.Define("BTC", 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := units.Define("W", 1, "J/s"); err == nil {
+		t.Error("Define(W) succeeded, want an error as it is already defined")
+	}
+	if err := units.Define("x/y", 1, ""); err == nil {
+		t.Error("Define(x/y) succeeded, want an error")
+	}
+	kcal, err := units.Parse("kcal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, _ := units.Parse("J")
+	if k, _ := kcal.Factor(j); k != 4184 {
+		t.Errorf("kcal.Factor(J) = %v, want 4184", k)
+	}
+	btc, _ := units.Parse("mBTC")
+	eur, _ := units.Parse("EUR")
+	if btc.Compatible(eur) {
+		t.Error("mBTC.Compatible(EUR) = true, want a dimension of its own")
+	}
+}